@@ -0,0 +1,178 @@
+package ajson
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+)
+
+// Keys of objects, which present values of binary encodings, that have no JSON representation
+const (
+	// binaryKey is the key of the byte string: {"$binary": "base64"}
+	binaryKey = "$binary"
+	// extKey is the key of the type of the MessagePack extension: {"$ext": 1, "$binary": "base64"}
+	extKey = "$ext"
+	// tagKey and tagValueKey are keys of the CBOR tagged value: {"$tag": 1, "$value": 1700000000}
+	tagKey      = "$tag"
+	tagValueKey = "$value"
+)
+
+// binaryMaxDepth is the maximum nesting level of values of binary encodings, scalars included: decoders are recursive
+const binaryMaxDepth = 10000
+
+// binaryReader reads values of binary encodings
+type binaryReader struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// binaryNode returns the object, which presents the byte string
+func binaryNode(data []byte) *Node {
+	result := ObjectNode("", nil)
+	_ = result.AppendObject(binaryKey, StringNode("", base64.StdEncoding.EncodeToString(data)))
+	return result
+}
+
+// binaryData returns the byte string of the object {"$binary": "base64"}
+func binaryData(node *Node) ([]byte, bool) {
+	if len(node.children) != 1 {
+		return nil, false
+	}
+	return binaryMember(node)
+}
+
+// binaryMember returns the byte string of the "$binary" member of the object
+func binaryMember(node *Node) ([]byte, bool) {
+	member, ok := node.members[binaryKey]
+	if !ok || !member.IsString() {
+		return nil, false
+	}
+	value, err := member.GetString()
+	if err != nil {
+		return nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	return data, err == nil
+}
+
+// binaryInteger returns the integer value of the member of the object, if it's in range [min, max]
+func binaryInteger(node *Node, key string, min, max float64) (float64, bool) {
+	member, ok := node.members[key]
+	if !ok || !member.IsNumeric() {
+		return 0, false
+	}
+	value, err := member.GetNumeric()
+	if err != nil || value != math.Trunc(value) || value < min || value > max {
+		return 0, false
+	}
+	return value, true
+}
+
+// binaryKeyOf returns the key of the object by the decoded key of the map: strings as is and numbers as integers
+func binaryKeyOf(node *Node) (string, bool) {
+	switch node._type {
+	case String:
+		value, err := node.GetString()
+		return value, err == nil
+	case Numeric:
+		value, err := node.GetNumeric()
+		return strconv.FormatFloat(value, 'f', -1, 64), err == nil
+	}
+	return "", false
+}
+
+// isUnsigned checks if the value is the integer, which fits into uint64
+func isUnsigned(value float64) bool {
+	return value == math.Trunc(value) && value >= 0 && value < 1<<64 && !math.Signbit(value)
+}
+
+// isSigned checks if the value is the negative integer, which fits into int64
+func isSigned(value float64) bool {
+	return value == math.Trunc(value) && value < 0 && value >= -1<<63
+}
+
+// isFloat32 checks if the value can be stored as float32 without loss of precision
+func isFloat32(value float64) bool {
+	return float64(float32(value)) == value || math.IsNaN(value)
+}
+
+// appendUint appends the big-endian value of the size in bytes
+func appendUint(result []byte, value uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		result = append(result, byte(value>>(uint(i)*8)))
+	}
+	return result
+}
+
+func (r *binaryReader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, r.errorEOF()
+	}
+	r.pos++
+	return r.data[r.pos-1], nil
+}
+
+// next returns the next size bytes
+func (r *binaryReader) next(size uint64) ([]byte, error) {
+	if size > uint64(len(r.data)-r.pos) {
+		return nil, r.errorEOF()
+	}
+	start := r.pos
+	r.pos += int(size)
+	return r.data[start:r.pos], nil
+}
+
+// uint returns the big-endian value of the size in bytes
+func (r *binaryReader) uint(size int) (uint64, error) {
+	data, err := r.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var result uint64
+	for _, b := range data {
+		result = result<<8 | uint64(b)
+	}
+	return result, nil
+}
+
+// float returns the IEEE 754 value of the size in bytes: 4 or 8
+func (r *binaryReader) float(size int) (float64, error) {
+	bits, err := r.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if size == 4 {
+		return float64(math.Float32frombits(uint32(bits))), nil
+	}
+	return math.Float64frombits(bits), nil
+}
+
+// enter increases the nesting level of the value at the start, error of type MaxDepthExceeded is returned,
+// if the level is deeper than binaryMaxDepth; leave should be called after the value is read
+func (r *binaryReader) enter(start int) error {
+	if r.depth++; r.depth > binaryMaxDepth {
+		return errorLimit(MaxDepthExceeded, start, binaryMaxDepth)
+	}
+	return nil
+}
+
+// leave decreases the nesting level
+func (r *binaryReader) leave() {
+	r.depth--
+}
+
+// end checks that all data was read
+func (r *binaryReader) end() error {
+	if r.pos != len(r.data) {
+		return errorAt(r.pos, r.data[r.pos])
+	}
+	return nil
+}
+
+func (r *binaryReader) errorEOF() error {
+	return Error{
+		Type:  UnexpectedEOF,
+		Index: r.pos,
+	}
+}
@@ -0,0 +1,51 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestBinaryData(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{input: `{"$binary": "AQI="}`, expected: "\x01\x02", ok: true},
+		{input: `{"$binary": ""}`, expected: "", ok: true},
+		{input: `{"$binary": "AQI"}`},
+		{input: `{"$binary": 1}`},
+		{input: `{"$binary": "AQI=", "a": 1}`},
+		{input: `{"binary": "AQI="}`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			data, ok := binaryData(Must(Unmarshal([]byte(test.input))))
+			if ok != test.ok || string(data) != test.expected {
+				t.Errorf("Wrong result: %q, %v", data, ok)
+			}
+		})
+	}
+}
+
+func TestBinary_roundTrip(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": [null, true, 1, -1, 1.5, 1e300, "ü"], "b": {"$binary": "AQI="}, "c": {"$ext": 5, "$binary": "AQ=="}, "d": {}}`)))
+	for name, codec := range map[string]struct {
+		marshal   func(*Node) ([]byte, error)
+		unmarshal func([]byte) (*Node, error)
+	}{
+		"msgpack": {marshal: MarshalMsgPack, unmarshal: UnmarshalMsgPack},
+		"cbor":    {marshal: MarshalCBOR, unmarshal: UnmarshalCBOR},
+	} {
+		data, err := codec.marshal(root)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", name, err)
+			continue
+		}
+		result, err := codec.unmarshal(data)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", name, err)
+		} else if ok, err := result.Eq(root); err != nil || !ok {
+			t.Errorf("Wrong result for %s: %s, expected: %s", name, result, root)
+		}
+	}
+}
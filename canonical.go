@@ -0,0 +1,176 @@
+package ajson
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MarshalCanonical returns the canonical form of the value, as described in RFC 8785 (JSON Canonicalization Scheme):
+// keys are sorted by UTF-16 code units, numbers are formatted as in ECMAScript, strings have minimal escaping and
+// there are no whitespaces. Unchanged values are marshaled again as well, so the result doesn't depend on the source.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"b": 1.50, "a": [1E3, "€"]}`)))
+//	result, _ := MarshalCanonical(root)
+//	// result == []byte(`{"a":[1000,"€"],"b":1.5}`)
+//
+func MarshalCanonical(node *Node) (result []byte, err error) {
+	return appendCanonical(make([]byte, 0), node)
+}
+
+func appendCanonical(result []byte, node *Node) ([]byte, error) {
+	if node == nil || !node.dirty && !node.ready() {
+		return nil, errorUnparsed()
+	}
+	var err error
+	switch node._type {
+	case Null:
+		result = append(result, _null...)
+	case Numeric:
+		var value float64
+		if value, err = node.GetNumeric(); err != nil {
+			return nil, err
+		}
+		if result, err = appendCanonicalNumber(result, value); err != nil {
+			return nil, err
+		}
+	case String:
+		var value string
+		if value, err = node.GetString(); err != nil {
+			return nil, err
+		}
+		result = appendCanonicalString(result, value)
+	case Bool:
+		var value bool
+		if value, err = node.GetBool(); err != nil {
+			return nil, err
+		} else if value {
+			result = append(result, _true...)
+		} else {
+			result = append(result, _false...)
+		}
+	case Array:
+		result = append(result, bracketL)
+		for i, child := range node.children {
+			if i != 0 {
+				result = append(result, coma)
+			}
+			if result, err = appendCanonical(result, child); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, bracketR)
+	case Object:
+		children := make([]*Node, len(node.children))
+		keys := make(map[*Node][]uint16, len(node.children))
+		for i, child := range node.children {
+			if child == nil {
+				return nil, errorUnparsed()
+			}
+			children[i] = child
+			keys[child] = utf16.Encode([]rune(child.Key()))
+		}
+		sort.SliceStable(children, func(i, j int) bool {
+			return lessUTF16(keys[children[i]], keys[children[j]])
+		})
+		result = append(result, bracesL)
+		for i, child := range children {
+			if i != 0 {
+				result = append(result, coma)
+			}
+			result = appendCanonicalString(result, child.Key())
+			result = append(result, colon)
+			if result, err = appendCanonical(result, child); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, bracesR)
+	default:
+		return nil, errorType()
+	}
+	return result, nil
+}
+
+// appendCanonicalNumber appends the number in the same format as ECMAScript Number.prototype.toString does
+func appendCanonicalNumber(result []byte, value float64) ([]byte, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, errorRequest("unsupported value: %v", value)
+	}
+	if value == 0 {
+		return append(result, '0'), nil
+	}
+	if abs := math.Abs(value); abs >= 1e-6 && abs < 1e21 {
+		return strconv.AppendFloat(result, value, 'f', -1, 64), nil
+	}
+	// ECMAScript uses the exponent without leading zeros: 1e-7 instead of 1e-07
+	start := len(result)
+	result = strconv.AppendFloat(result, value, 'e', -1, 64)
+	for i := len(result) - 1; i > start; i-- {
+		if result[i] == 'e' {
+			if result[i+2] == '0' {
+				result = append(result[:i+2], result[i+3:]...)
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+// appendCanonicalString appends the quoted string, where only quotes, backslashes and control characters are escaped
+func appendCanonicalString(result []byte, value string) []byte {
+	result = append(result, quotes)
+	start := 0
+	for i := 0; i < len(value); {
+		b := value[i]
+		if b >= 0x20 && b != quotes && b != backslash && b < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if b >= utf8.RuneSelf {
+			c, size := utf8.DecodeRuneInString(value[i:])
+			if c != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+			result = append(result, value[start:i]...)
+			result = append(result, "\uFFFD"...)
+			i++
+			start = i
+			continue
+		}
+		result = append(result, value[start:i]...)
+		switch b {
+		case quotes, backslash:
+			result = append(result, backslash, b)
+		case '\b':
+			result = append(result, backslash, 'b')
+		case '\f':
+			result = append(result, backslash, 'f')
+		case '\n':
+			result = append(result, backslash, 'n')
+		case '\r':
+			result = append(result, backslash, 'r')
+		case '\t':
+			result = append(result, backslash, 't')
+		default:
+			result = append(result, backslash, 'u', '0', '0', hex[b>>4], hex[b&0xF])
+		}
+		i++
+		start = i
+	}
+	result = append(result, value[start:]...)
+	return append(result, quotes)
+}
+
+// lessUTF16 compares strings, encoded as UTF-16 code units
+func lessUTF16(left, right []uint16) bool {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] != right[i] {
+			return left[i] < right[i]
+		}
+	}
+	return len(left) < len(right)
+}
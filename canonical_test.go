@@ -0,0 +1,147 @@
+package ajson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			// RFC 8785, section 3.2.2
+			name: "rfc: sample",
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785, section 3.2.3
+			name: "rfc: sorting",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:     "arrays",
+			input:    `[56, {"d": true, "10": null, "1": [ ]}]`,
+			expected: `[56,{"1":[],"10":null,"d":true}]`,
+		},
+		{
+			name:     "not locale-aware",
+			input:    `{"peach": "This sorting order", "péché": "is wrong according to French", "pêche": "but canonicalization MUST", "sin": "ignore locale"}`,
+			expected: `{"peach":"This sorting order","péché":"is wrong according to French","pêche":"but canonicalization MUST","sin":"ignore locale"}`,
+		},
+		{
+			name:     "nested",
+			input:    `{"b": {"z": 1, "y": [{"b": 2, "a": 1}]}, "a": "<&>"}`,
+			expected: `{"a":"<&>","b":{"y":[{"a":1,"b":2}],"z":1}}`,
+		},
+		{
+			name:     "escapes",
+			input:    `"\b\f\n\r\t\u0001\u001f\u007f\u2028"`,
+			expected: "\"\\b\\f\\n\\r\\t\\u0001\\u001f\u007f\u2028\"",
+		},
+		{name: "integer", input: `1E3`, expected: `1000`},
+		{name: "negative zero", input: `-0.0`, expected: `0`},
+		{name: "whitespaces", input: " [ 1 , { } , [ ] ] ", expected: `[1,{},[]]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := MarshalCanonical(Must(Unmarshal([]byte(test.input))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalCanonical_numbers(t *testing.T) {
+	// RFC 8785, appendix B
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{
+		{bits: 0x0000000000000000, expected: "0"},
+		{bits: 0x8000000000000000, expected: "0"},
+		{bits: 0x0000000000000001, expected: "5e-324"},
+		{bits: 0x8000000000000001, expected: "-5e-324"},
+		{bits: 0x7fefffffffffffff, expected: "1.7976931348623157e+308"},
+		{bits: 0xffefffffffffffff, expected: "-1.7976931348623157e+308"},
+		{bits: 0x4340000000000000, expected: "9007199254740992"},
+		{bits: 0xc340000000000000, expected: "-9007199254740992"},
+		{bits: 0x4430000000000000, expected: "295147905179352830000"},
+		{bits: 0x44b52d02c7e14af5, expected: "9.999999999999997e+22"},
+		{bits: 0x44b52d02c7e14af6, expected: "1e+23"},
+		{bits: 0x44b52d02c7e14af7, expected: "1.0000000000000001e+23"},
+		{bits: 0x444b1ae4d6e2ef4e, expected: "999999999999999700000"},
+		{bits: 0x444b1ae4d6e2ef4f, expected: "999999999999999900000"},
+		{bits: 0x444b1ae4d6e2ef50, expected: "1e+21"},
+		{bits: 0x3eb0c6f7a0b5ed8c, expected: "9.999999999999997e-7"},
+		{bits: 0x3eb0c6f7a0b5ed8d, expected: "0.000001"},
+		{bits: 0x41b3de4355555553, expected: "333333333.3333332"},
+		{bits: 0x41b3de4355555554, expected: "333333333.33333325"},
+		{bits: 0x41b3de4355555555, expected: "333333333.3333333"},
+		{bits: 0x41b3de4355555556, expected: "333333333.3333334"},
+		{bits: 0x41b3de4355555557, expected: "333333333.33333343"},
+		{bits: 0xbecbf647612f3696, expected: "-0.0000033333333333333333"},
+		{bits: 0x43143ff3c1cb0959, expected: "1424953923781206.2"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			result, err := MarshalCanonical(NumericNode("", math.Float64frombits(test.bits)))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalCanonical_errors(t *testing.T) {
+	broken := Must(Unmarshal([]byte(`{"a": 1}`)))
+	broken.borders[1] = 0
+	tests := map[string]*Node{
+		"nil":      nil,
+		"NaN":      NumericNode("", math.NaN()),
+		"Infinity": ArrayNode("", []*Node{NumericNode("", math.Inf(1))}),
+		"broken":   broken,
+		"value":    ObjectNode("", map[string]*Node{"key": valueNode(nil, "key", Bool, 1)}),
+	}
+	for name, node := range tests {
+		t.Run(name, func(t *testing.T) {
+			if result, err := MarshalCanonical(node); err == nil {
+				t.Errorf("Expected error, got: %s", result)
+			}
+		})
+	}
+}
+
+func TestMarshalCanonical_changed(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"b": [1.0, 2], "a": "x"}`)))
+	_ = root.MustKey("b").AppendArray(NumericNode("", 0.1))
+	_ = root.AppendObject("c", StringNode("", "é"))
+	result, err := MarshalCanonical(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if expected := `{"a":"x","b":[1,2,0.1],"c":"é"}`; string(result) != expected {
+		t.Errorf("Wrong result: %s, expected: %s", result, expected)
+	}
+}
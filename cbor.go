@@ -0,0 +1,344 @@
+package ajson
+
+import (
+	"math"
+)
+
+// cborBreak is the stop code of indefinite-length items
+const cborBreak = 0xff
+
+// MarshalCBOR returns the CBOR encoding of the node, as described in RFC 8949. Integers are written as integers,
+// other numbers as float32 if it keeps the value, or as float64. All lengths are definite.
+// Objects {"$binary": "base64"} are written as byte strings, objects {"$tag": number, "$value": value} as tags.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"a": [1, -2], "b": {"$tag": 1, "$value": 1700000000}}`)))
+//	result, _ := MarshalCBOR(root)
+//	// result == []byte{0xa2, 0x61, 'a', 0x82, 0x01, 0x21, 0x61, 'b', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00}
+//
+func MarshalCBOR(node *Node) ([]byte, error) {
+	return appendCBOR(make([]byte, 0), node)
+}
+
+// UnmarshalCBOR decodes the CBOR value into the node. Byte strings become objects {"$binary": "base64"},
+// tags become objects {"$tag": number, "$value": value}, undefined becomes null, keys of maps should be strings
+// or integers. Nesting of values, scalars included, is limited by 10000 levels.
+// Example:
+//
+//	root, _ := UnmarshalCBOR([]byte{0xa2, 0x61, 'a', 0x20, 0x61, 'b', 0x41, 0xff})
+//	// root.String() == `{"a":-1,"b":{"$binary":"/w=="}}`
+//
+func UnmarshalCBOR(data []byte) (*Node, error) {
+	r := &binaryReader{data: data}
+	node, err := r.cbor()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.end(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func appendCBOR(result []byte, node *Node) ([]byte, error) {
+	if node == nil || !node.dirty && !node.ready() {
+		return nil, errorUnparsed()
+	}
+	var err error
+	switch node._type {
+	case Null:
+		result = append(result, 0xf6)
+	case Bool:
+		var value bool
+		if value, err = node.GetBool(); err != nil {
+			return nil, err
+		} else if value {
+			result = append(result, 0xf5)
+		} else {
+			result = append(result, 0xf4)
+		}
+	case Numeric:
+		var value float64
+		if value, err = node.GetNumeric(); err != nil {
+			return nil, err
+		}
+		switch {
+		case isUnsigned(value):
+			result = appendCBORHead(result, 0, uint64(value))
+		case isSigned(value):
+			result = appendCBORHead(result, 1, uint64(-1-int64(value)))
+		case isFloat32(value):
+			result = appendUint(append(result, 0xfa), uint64(math.Float32bits(float32(value))), 4)
+		default:
+			result = appendUint(append(result, 0xfb), math.Float64bits(value), 8)
+		}
+	case String:
+		var value string
+		if value, err = node.GetString(); err != nil {
+			return nil, err
+		}
+		result = append(appendCBORHead(result, 3, uint64(len(value))), value...)
+	case Array:
+		result = appendCBORHead(result, 4, uint64(len(node.children)))
+		for _, child := range node.children {
+			if result, err = appendCBOR(result, child); err != nil {
+				return nil, err
+			}
+		}
+	case Object:
+		if data, ok := binaryData(node); ok {
+			return append(appendCBORHead(result, 2, uint64(len(data))), data...), nil
+		}
+		if tag, ok := cborTag(node); ok {
+			return appendCBOR(appendCBORHead(result, 6, uint64(tag)), node.members[tagValueKey])
+		}
+		result = appendCBORHead(result, 5, uint64(len(node.children)))
+		for _, child := range node.children {
+			if child == nil {
+				return nil, errorUnparsed()
+			}
+			key := child.Key()
+			result = append(appendCBORHead(result, 3, uint64(len(key))), key...)
+			if result, err = appendCBOR(result, child); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errorType()
+	}
+	return result, nil
+}
+
+// appendCBORHead appends the major type and the argument in the shortest form
+func appendCBORHead(result []byte, major byte, argument uint64) []byte {
+	major <<= 5
+	switch {
+	case argument < 24:
+		return append(result, major|byte(argument))
+	case argument <= math.MaxUint8:
+		return append(result, major|24, byte(argument))
+	case argument <= math.MaxUint16:
+		return appendUint(append(result, major|25), argument, 2)
+	case argument <= math.MaxUint32:
+		return appendUint(append(result, major|26), argument, 4)
+	}
+	return appendUint(append(result, major|27), argument, 8)
+}
+
+// cborTag returns the tag number of the object {"$tag": number, "$value": value}
+func cborTag(node *Node) (float64, bool) {
+	if len(node.children) != 2 || !node.HasKey(tagValueKey) {
+		return 0, false
+	}
+	tag, ok := binaryInteger(node, tagKey, 0, math.MaxUint64)
+	return tag, ok && isUnsigned(tag)
+}
+
+func (r *binaryReader) cbor() (*Node, error) {
+	start := r.pos
+	defer r.leave()
+	if err := r.enter(start); err != nil {
+		return nil, err
+	}
+	code, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := code>>5, code&0x1f
+	if major == 7 {
+		return r.cborSimple(start, info)
+	}
+	if info == 31 {
+		return r.cborIndefinite(start, major)
+	}
+	argument, err := r.cborArgument(start, info)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		return NumericNode("", float64(argument)), nil
+	case 1:
+		return NumericNode("", -1-float64(argument)), nil
+	case 2:
+		data, err := r.next(argument)
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode(data), nil
+	case 3:
+		data, err := r.next(argument)
+		if err != nil {
+			return nil, err
+		}
+		return StringNode("", string(data)), nil
+	case 4:
+		children := make([]*Node, 0)
+		for i := uint64(0); i < argument; i++ {
+			child, err := r.cbor()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+		return ArrayNode("", children), nil
+	case 5:
+		result := ObjectNode("", nil)
+		for i := uint64(0); i < argument; i++ {
+			if err = r.cborPair(result); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	value, err := r.cbor()
+	if err != nil {
+		return nil, err
+	}
+	result := ObjectNode("", nil)
+	_ = result.AppendObject(tagKey, NumericNode("", float64(argument)))
+	_ = result.AppendObject(tagValueKey, value)
+	return result, nil
+}
+
+// cborArgument returns the argument of the head by its additional information
+func (r *binaryReader) cborArgument(start int, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info < 28:
+		return r.uint(1 << (info - 24))
+	}
+	return 0, errorAt(start, r.data[start])
+}
+
+// cborSimple returns the node of the simple value or the float
+func (r *binaryReader) cborSimple(start int, info byte) (*Node, error) {
+	switch info {
+	case 20:
+		return BoolNode("", false), nil
+	case 21:
+		return BoolNode("", true), nil
+	case 22, 23:
+		return NullNode(""), nil
+	case 25:
+		bits, err := r.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("", halfFloat(uint16(bits))), nil
+	case 26, 27:
+		value, err := r.float(4 << (info - 26))
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("", value), nil
+	}
+	return nil, errorAt(start, r.data[start])
+}
+
+// cborIndefinite returns the node of the indefinite-length item: chunks of strings, items of arrays or pairs of maps,
+// which are followed by the break code
+func (r *binaryReader) cborIndefinite(start int, major byte) (*Node, error) {
+	var (
+		data     []byte
+		children []*Node
+		result   *Node
+	)
+	switch major {
+	case 2, 3:
+		data = make([]byte, 0)
+	case 4:
+		children = make([]*Node, 0)
+	case 5:
+		result = ObjectNode("", nil)
+	default:
+		return nil, errorAt(start, r.data[start])
+	}
+	for {
+		if r.pos >= len(r.data) {
+			return nil, r.errorEOF()
+		}
+		if r.data[r.pos] == cborBreak {
+			r.pos++
+			break
+		}
+		switch major {
+		case 2, 3:
+			// chunks are definite-length strings of the same major type
+			chunk := r.pos
+			code, _ := r.byte()
+			if code>>5 != major || code&0x1f == 31 {
+				return nil, errorAt(chunk, code)
+			}
+			size, err := r.cborArgument(chunk, code&0x1f)
+			if err != nil {
+				return nil, err
+			}
+			part, err := r.next(size)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, part...)
+		case 4:
+			child, err := r.cbor()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		case 5:
+			if err := r.cborPair(result); err != nil {
+				return nil, err
+			}
+		}
+	}
+	switch major {
+	case 2:
+		return binaryNode(data), nil
+	case 3:
+		return StringNode("", string(data)), nil
+	case 4:
+		return ArrayNode("", children), nil
+	}
+	return result, nil
+}
+
+// cborPair reads the key and the value of the map into the object
+func (r *binaryReader) cborPair(result *Node) error {
+	start := r.pos
+	key, err := r.cbor()
+	if err != nil {
+		return err
+	}
+	name, ok := binaryKeyOf(key)
+	if !ok {
+		return errorAt(start, r.data[start])
+	}
+	value, err := r.cbor()
+	if err != nil {
+		return err
+	}
+	return result.AppendObject(name, value)
+}
+
+// halfFloat returns the value of IEEE 754 half-precision float
+func halfFloat(bits uint16) float64 {
+	exponent, mantissa := int(bits>>10&0x1f), float64(bits&0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if bits&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,170 @@
+package ajson
+
+import (
+	"bytes"
+	hexadecimal "encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestUnmarshalCBOR(t *testing.T) {
+	// RFC 8949, appendix A
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "00", expected: `0`},
+		{input: "17", expected: `23`},
+		{input: "1818", expected: `24`},
+		{input: "1903e8", expected: `1000`},
+		{input: "1a000f4240", expected: `1e+06`},
+		{input: "1b000000e8d4a51000", expected: `1e+12`},
+		{input: "20", expected: `-1`},
+		{input: "3863", expected: `-100`},
+		{input: "f90000", expected: `0`},
+		{input: "f93c00", expected: `1`},
+		{input: "f93e00", expected: `1.5`},
+		{input: "f97bff", expected: `65504`},
+		{input: "f90001", expected: `5.960464477539063e-08`},
+		{input: "f9c400", expected: `-4`},
+		{input: "fa47c35000", expected: `100000`},
+		{input: "fb3ff199999999999a", expected: `1.1`},
+		{input: "f4", expected: `false`},
+		{input: "f5", expected: `true`},
+		{input: "f6", expected: `null`},
+		{input: "f7", expected: `null`},
+		{input: "c074323031332d30332d32315432303a30343a30305a", expected: `{"$tag":0,"$value":"2013-03-21T20:04:00Z"}`},
+		{input: "40", expected: `{"$binary":""}`},
+		{input: "4401020304", expected: `{"$binary":"AQIDBA=="}`},
+		{input: "60", expected: `""`},
+		{input: "6449455446", expected: `"IETF"`},
+		{input: "62c3bc", expected: `"ü"`},
+		{input: "80", expected: `[]`},
+		{input: "8301820203820405", expected: `[1,[2,3],[4,5]]`},
+		{input: "a201020304", expected: `{"1":2,"3":4}`},
+		{input: "a26161016162820203", expected: `{"a":1,"b":[2,3]}`},
+		{input: "5f42010243030405ff", expected: `{"$binary":"AQIDBAU="}`},
+		{input: "7f657374726561646d696e67ff", expected: `"streaming"`},
+		{input: "9f018202039f0405ffff", expected: `[1,[2,3],[4,5]]`},
+		{input: "bf61610161629f0203ffff", expected: `{"a":1,"b":[2,3]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			data, _ := hexadecimal.DecodeString(test.input)
+			root, err := UnmarshalCBOR(data)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result := root.String(); result != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCBOR_special(t *testing.T) {
+	tests := map[string]float64{
+		"f97c00":             math.Inf(1),
+		"f9fc00":             math.Inf(-1),
+		"fb7ff0000000000000": math.Inf(1),
+	}
+	for input, expected := range tests {
+		data, _ := hexadecimal.DecodeString(input)
+		root, err := UnmarshalCBOR(data)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if value := root.MustNumeric(); value != expected {
+			t.Errorf("Wrong value for %s: %v", input, value)
+		}
+	}
+	data, _ := hexadecimal.DecodeString("f97e00")
+	if root, err := UnmarshalCBOR(data); err != nil || !math.IsNaN(root.MustNumeric()) {
+		t.Errorf("Expected NaN: %v", err)
+	}
+}
+
+func TestUnmarshalCBOR_errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{input: "", err: "unexpected end of file"},
+		{input: "18", err: "unexpected end of file"},
+		{input: "62c3", err: "unexpected end of file"},
+		{input: "8201", err: "unexpected end of file"},
+		{input: "9f01", err: "unexpected end of file"},
+		{input: "1c", err: "wrong symbol '\x1c' at 0"},
+		{input: "ff", err: "wrong symbol '\xff' at 0"},
+		{input: "1f", err: "wrong symbol '\x1f' at 0"},
+		{input: "5f6161ff", err: "wrong symbol 'a' at 1"},
+		{input: "a1f601", err: "wrong symbol '\xf6' at 1"},
+		{input: "0001", err: "wrong symbol '\x01' at 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			data, _ := hexadecimal.DecodeString(test.input)
+			_, err := UnmarshalCBOR(data)
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCBOR_depth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		err   string
+	}{
+		{name: "array", input: append(bytes.Repeat([]byte{0x81}, 9999), 0xf6)},
+		{name: "deep array", input: append(bytes.Repeat([]byte{0x81}, 10001), 0xf6), err: "max depth 10000 exceeded at 10000"},
+		{name: "deep indefinite array", input: bytes.Repeat([]byte{0x9f}, 10001), err: "max depth 10000 exceeded at 10000"},
+		{name: "deep tag", input: append(bytes.Repeat([]byte{0xc1}, 10001), 0x00), err: "max depth 10000 exceeded at 10000"},
+		{name: "deep map", input: append(bytes.Repeat([]byte{0xa1, 0x00}, 10001), 0xf6), err: "max depth 10000 exceeded at 19999"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalCBOR(test.input)
+			if test.err == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("Wrong error: %v, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestMarshalCBOR(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `0`, expected: "00"},
+		{input: `24`, expected: "1818"},
+		{input: `1000000000000`, expected: "1b000000e8d4a51000"},
+		{input: `18446744073709551615`, expected: "fa5f800000"},
+		{input: `-100`, expected: "3863"},
+		{input: `-0.0`, expected: "fa80000000"},
+		{input: `1.5`, expected: "fa3fc00000"},
+		{input: `1.1`, expected: "fb3ff199999999999a"},
+		{input: `[true, false, null]`, expected: "83f5f4f6"},
+		{input: `"ü"`, expected: "62c3bc"},
+		{input: `{"a": 1, "b": [2, 3]}`, expected: "a26161016162820203"},
+		{input: `{"$binary": "AQIDBA=="}`, expected: "4401020304"},
+		{input: `{"$binary": "wrong"}`, expected: "a1672462696e6172796577726f6e67"},
+		{input: `{"$tag": 1, "$value": 1700000000}`, expected: "c11a6553f100"},
+		{input: `{"$tag": -1, "$value": 1}`, expected: "a2642474616720662476616c756501"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result, err := MarshalCBOR(Must(Unmarshal([]byte(test.input))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if value := hexadecimal.EncodeToString(result); value != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", value, test.expected)
+			}
+		})
+	}
+}
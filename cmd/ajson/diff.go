@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spyzhov/ajson"
+)
+
+func diff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	ignoreOrder := flags.Bool("ignore-order", false, "compare arrays regardless of the order of elements")
+	tolerance := flags.Float64("tolerance", 0, "maximum difference between numbers to be treated as equal")
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Println(`Usage: ajson diff [options] [--] "old" "new"
+  Compare two JSON documents and print the list of changes.
+  Exit status is 0 if documents are equal, 1 if they are different.
+Argument:
+  old        Path to the first JSON file or URL. Use "-" to read it from STDIN.
+  new        Path to the second JSON file or URL.
+  --         End of options: next arguments are not options, even if they start with '-'.
+Options:`)
+		flags.PrintDefaults()
+		fmt.Println(`Examples:
+  ajson diff old.json new.json
+  ajson diff --ignore-order --tolerance=0.001 expected.json actual.json`)
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	changes, err := ajson.DiffWithOptions(read(flags.Arg(0), ajson.Options{}), read(flags.Arg(1), ajson.Options{}), ajson.DiffOptions{
+		IgnoreArrayOrder: *ignoreOrder,
+		FloatTolerance:   *tolerance,
+	})
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+
+	result := ajson.ArrayNode("", nil)
+	for _, change := range changes {
+		node := ajson.ObjectNode("", nil)
+		_ = node.AppendObject("path", ajson.StringNode("", change.Path))
+		_ = node.AppendObject("kind", ajson.StringNode("", change.Kind.String()))
+		if change.Old != nil {
+			_ = node.AppendObject("old", change.Old.Clone())
+		}
+		if change.New != nil {
+			_ = node.AppendObject("new", change.New.Clone())
+		}
+		_ = result.AppendArray(node)
+	}
+	write(result)
+	if len(changes) != 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spyzhov/ajson"
+)
+
+func flatten(args []string) {
+	flattenCommand("flatten", "Convert JSON document into the object with single-level keys.", ajson.Flatten, args)
+}
+
+func unflatten(args []string) {
+	flattenCommand("unflatten", "Convert the object with single-level keys into nested JSON document.", ajson.Unflatten, args)
+}
+
+func flattenCommand(name string, description string, convert func(*ajson.Node, string) (*ajson.Node, error), args []string) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	separator := flags.String("separator", ".", "separator of keys")
+	flags.Usage = func() {
+		fmt.Printf(`Usage: ajson %[1]s [options] [--] ["input"]
+  %[2]s
+Argument:
+  input        Path to the JSON file or URL, STDIN by default.
+  --           End of options: next arguments are not options, even if they start with '-'.
+Options:
+  --separator  Separator of keys (default "."), it's escaped with '\' in keys.
+Examples:
+  ajson %[1]s config.json
+  ajson %[1]s --separator __ config.json
+`, name, description)
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	result, err := convert(read(flags.Arg(0), ajson.Options{}), *separator)
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+	write(result)
+}
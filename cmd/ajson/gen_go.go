@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spyzhov/ajson"
+	"github.com/spyzhov/ajson/gogen"
+)
+
+func genGo(args []string) {
+	flags := flag.NewFlagSet("gen-go", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	name := flags.String("name", "Root", "name of the root type")
+	pkg := flags.String("package", "", "name of the package")
+	flags.Usage = func() {
+		fmt.Println(`Usage: ajson gen-go [options] [--] "input" ["input"...]
+  Generate Go type definitions with json tags from sample documents.
+Argument:
+  input      Path to the JSON file or URL. Use "-" to read it from STDIN.
+  --         End of options: next arguments are not options, even if they start with '-'.
+Options:
+  --name     Name of the root type (default "Root").
+  --package  Name of the package, the package clause is omitted if empty.
+Examples:
+  ajson gen-go --name User user1.json user2.json
+  curl -s "https://randomuser.me/api/?results=10" | ajson gen-go --package api -`)
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	samples := make([]*ajson.Node, 0, flags.NArg())
+	for _, input := range flags.Args() {
+		samples = append(samples, read(input, ajson.Options{}))
+	}
+	source, err := gogen.Generate(gogen.Options{Name: *name, Package: *pkg}, samples...)
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+	_, _ = os.Stdout.Write(source)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,21 +16,64 @@ import (
 
 var version = "v0.7.1"
 
-func usage() {
-	text := ``
-	if inArgs("-h", "-help", "--help", "help") || len(os.Args) > 3 {
-		text = `Usage: ajson "jsonpath" ["input"]
+var (
+	strict       = flag.Bool("strict", false, "reject objects with duplicate keys")
+	noHTMLEscape = flag.Bool("no-html-escape", false, "keep '<', '>' and '&' in strings as is")
+	noLineEscape = flag.Bool("no-line-escape", false, "keep U+2028 and U+2029 in strings as is")
+	asciiOnly    = flag.Bool("ascii", false, "escape all non-ASCII characters")
+	from         = flag.String("from", "json", "format of the input: json, yaml, xml, csv, tsv, msgpack or cbor")
+	to           = flag.String("to", "json", "format of the output: json, yaml, xml, csv, tsv, msgpack or cbor")
+	showVersion  = flag.Bool("version", false, "print the version")
+)
+
+func init() {
+	flag.StringVar(to, "output", "json", "alias of --to")
+}
+
+func help() {
+	fmt.Println(`Usage: ajson [options] [--] "jsonpath" ["input"]
   Read JSON and evaluate it with JSONPath.
 Argument:
   jsonpath   Valid JSONPath or evaluate string (Examples: "$..[?(@.price)]", "$..price", "avg($..price)")
   input      Path to the JSON file. Leave it blank to use STDIN.
+  --         End of options: next arguments are not options, even if they start with '-'.
+Options:
+  --strict          Reject objects with duplicate keys.
+  --no-html-escape  Keep '<', '>' and '&' in strings as is.
+  --no-line-escape  Keep U+2028 and U+2029 in strings as is.
+  --ascii           Escape all non-ASCII characters as \uXXXX.
+  --from format     Format of the input: json, yaml, xml, csv, tsv, msgpack or cbor, "json" by default.
+  --to format       Format of the output: json, yaml, xml, csv, tsv, msgpack or cbor, "json" by default.
+  --output format   Alias of --to.
+  --version         Print the version.
+Commands:
+  diff       Compare two JSON documents, see: ajson diff --help
+  flatten    Convert JSON document into single-level keys, see: ajson flatten --help
+  gen-go     Generate Go types from sample documents, see: ajson gen-go --help
+  schema     Infer JSON Schema from sample documents, see: ajson schema --help
+  unflatten  Convert single-level keys into JSON document, see: ajson unflatten --help
+  validate   Validate JSON document by JSON Schema, see: ajson validate --help
 Examples:
   ajson "avg($..registered.age)" "https://randomuser.me/api/?results=5000"
   ajson "$.results.*.name" "https://randomuser.me/api/?results=10"
   curl -s "https://randomuser.me/api/?results=10" | ajson "$..coordinates"
   ajson "$" example.json
-  echo "3" | ajson "2 * pi * $"`
-	} else if inArgs("version", "-version", "--version") {
+  echo "3" | ajson "2 * pi * $"
+  ajson --strict "$" example.json
+  ajson -- "-1 * $.price" example.json
+  ajson --ascii --no-html-escape "$" example.json
+  ajson --from yaml --to yaml "$.services.*.image" docker-compose.yml
+  ajson --from xml "$.feed.entry[*].title" "https://go.dev/blog/feed.atom"
+  ajson --output csv "$.results[*].name" "https://randomuser.me/api/?results=10"
+  ajson --from msgpack --to cbor "$.items[0]" data.msgpack > item.cbor`)
+}
+
+func usage() {
+	text := ``
+	if inArgs("-h", "-help", "--help", "help") || flag.NArg() > 2 {
+		help()
+		os.Exit(2)
+	} else if *showVersion || inArgs("version") {
 		text = fmt.Sprintf(`ajson: Version %s
 Copyright (c) 2020 Pyzhov Stepan
 MIT License <https://github.com/spyzhov/ajson/blob/master/LICENSE>
@@ -41,31 +86,40 @@ There is NO WARRANTY, to the extent permitted by law.`, version)
 	}
 }
 
+var commands = map[string]func(args []string){
+	"diff":      diff,
+	"flatten":   flatten,
+	"gen-go":    genGo,
+	"schema":    schemaCommand,
+	"unflatten": unflatten,
+	"validate":  validate,
+}
+
 func main() {
 	log.SetFlags(0)
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+	flag.Usage = help
+	flag.Parse()
 	usage()
-	if len(os.Args) < 2 {
+	if flag.NArg() < 1 {
 		log.Fatalf("JSONPath was not set")
 	}
-	path := os.Args[1]
-	input := getInput()
-	defer func() {
-		_ = input.Close()
-	}()
-	data, err := ioutil.ReadAll(input)
-
-	if err != nil {
-		log.Fatalf("error reading source: %s", err)
-	}
-	var result *ajson.Node
-
-	root, err := ajson.Unmarshal(data)
-	if err != nil {
-		log.Fatalf("error parsing JSON: %s", err)
+	path := flag.Arg(0)
+	input := flag.Arg(1)
+	options := ajson.Options{}
+	if *strict {
+		options.DuplicateKeys = ajson.DuplicateKeysReject
 	}
+	root := read(input, options)
 
+	var result *ajson.Node
 	var nodes []*ajson.Node
-	nodes, err = root.JSONPath(path)
+	nodes, err := root.JSONPath(path)
 	result = ajson.ArrayNode("", nodes)
 	if err != nil {
 		result, err = ajson.Eval(root, path)
@@ -74,19 +128,91 @@ func main() {
 		log.Fatalf("error: %s", err)
 	}
 
-	data, err = ajson.Marshal(result)
+	write(result)
+}
+
+// read returns the root node of the input: path to the file, URL or STDIN if input is blank
+func read(input string, options ajson.Options) *ajson.Node {
+	source := getInput(input)
+	defer func() {
+		_ = source.Close()
+	}()
+	data, err := ioutil.ReadAll(source)
 	if err != nil {
-		log.Fatalf("error preparing JSON: %s", err)
+		log.Fatalf("error reading source: %s", err)
 	}
-	fmt.Printf("%s\n", data)
+
+	var root *ajson.Node
+	switch *from {
+	case "json":
+		root, err = ajson.UnmarshalWithOptions(data, options)
+	case "yaml":
+		root, err = ajson.UnmarshalYAML(data)
+	case "xml":
+		root, err = ajson.FromXML(bytes.NewReader(data), ajson.XMLOptions{})
+	case "csv":
+		root, err = ajson.FromCSV(data, ajson.CSVOptions{})
+	case "tsv":
+		root, err = ajson.FromCSV(data, ajson.CSVOptions{Comma: '\t'})
+	case "msgpack":
+		root, err = ajson.UnmarshalMsgPack(data)
+	case "cbor":
+		root, err = ajson.UnmarshalCBOR(data)
+	default:
+		log.Fatalf("unknown input format: %s", *from)
+	}
+	if err != nil {
+		log.Fatalf("error parsing %s: %s", strings.ToUpper(*from), err)
+	}
+	return root
+}
+
+// write prints the node to the STDOUT in the output format
+func write(node *ajson.Node) {
+	var (
+		data []byte
+		err  error
+	)
+	switch *to {
+	case "json":
+		options := ajson.MarshalOptions{
+			DisableHTMLEscape:           *noHTMLEscape,
+			DisableLineTerminatorEscape: *noLineEscape,
+			ASCIIOnly:                   *asciiOnly,
+		}
+		if err = ajson.MarshalToWithOptions(os.Stdout, node, options); err != nil {
+			log.Fatalf("error preparing JSON: %s", err)
+		}
+		fmt.Println()
+		return
+	case "yaml":
+		data, err = ajson.MarshalYAML(node)
+	case "xml":
+		if data, err = ajson.ToXML(node, ajson.XMLOptions{Indent: "  "}); err == nil {
+			data = append(data, '\n')
+		}
+	case "csv":
+		data, err = ajson.ToCSV(node, ajson.CSVOptions{})
+	case "tsv":
+		data, err = ajson.ToCSV(node, ajson.CSVOptions{Comma: '\t'})
+	case "msgpack":
+		data, err = ajson.MarshalMsgPack(node)
+	case "cbor":
+		data, err = ajson.MarshalCBOR(node)
+	default:
+		log.Fatalf("unknown output format: %s", *to)
+	}
+	if err != nil {
+		log.Fatalf("error preparing %s: %s", strings.ToUpper(*to), err)
+	}
+	_, _ = os.Stdout.Write(data)
 }
 
-func getInput() io.ReadCloser {
-	if len(os.Args) < 3 {
+func getInput(input string) io.ReadCloser {
+	if input == "" || input == "-" {
 		return os.Stdin
 	}
 
-	input := os.Args[2]
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
 		resp, err := http.DefaultClient.Get(input)
 		if err != nil {
@@ -110,6 +236,9 @@ func inArgs(value ...string) bool {
 		index[val] = true
 	}
 	for _, val := range os.Args {
+		if val == "--" {
+			break
+		}
 		if index[val] {
 			return true
 		}
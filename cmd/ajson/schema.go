@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spyzhov/ajson"
+)
+
+func schemaCommand(args []string) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Println(`Usage: ajson schema infer [--] "input" ["input"...]
+  Infer JSON Schema (draft 2020-12) from sample documents.
+Argument:
+  input      Path to the JSON file or URL. Use "-" to read it from STDIN.
+  --         End of options: next arguments are not options, even if they start with '-'.
+Examples:
+  ajson schema infer response1.json response2.json
+  curl -s "https://randomuser.me/api/?results=10" | ajson schema infer -`)
+	}
+	// the subcommand is taken before options, so "--" can follow it
+	infer := len(args) != 0 && args[0] == "infer"
+	if infer {
+		args = args[1:]
+	}
+	_ = flags.Parse(args)
+	if !infer || flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	samples := make([]*ajson.Node, 0, flags.NArg())
+	for _, input := range flags.Args() {
+		samples = append(samples, read(input, ajson.Options{}))
+	}
+	write(ajson.InferSchema(samples...))
+}
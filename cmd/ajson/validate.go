@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spyzhov/ajson"
+	"github.com/spyzhov/ajson/schema"
+)
+
+func validate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	path := flags.String("schema", "", "path to the JSON Schema file or URL")
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Println(`Usage: ajson validate --schema "schema" [--] ["input"]
+  Validate JSON document by JSON Schema (draft 2020-12) and print all validation errors.
+  Exit status is 0 if document is valid, 1 if it is not.
+Argument:
+  input      Path to the JSON file or URL. Leave it blank to use STDIN.
+  --         End of options: next arguments are not options, even if they start with '-'.
+Options:`)
+		flags.PrintDefaults()
+		fmt.Println(`Examples:
+  ajson validate --schema schema.json document.json
+  cat document.json | ajson validate --schema "https://example.com/schema.json"`)
+	}
+	_ = flags.Parse(args)
+	if *path == "" || flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	compiled, err := schema.Compile(read(*path, ajson.Options{}))
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+	err = compiled.Validate(read(flags.Arg(0), ajson.Options{}))
+	if errors, ok := err.(schema.Errors); ok {
+		for _, current := range errors {
+			fmt.Println(current.Error())
+		}
+		os.Exit(1)
+	} else if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+}
@@ -0,0 +1,189 @@
+package ajson
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Collation compares two strings and returns an integer: 0 if a == b, -1 if a < b, and +1 if a > b.
+//
+// Any locale-aware collator can be used as the Collation, e.g.: collate.New(language.German).CompareString
+// from the golang.org/x/text/collate package.
+type Collation func(a, b string) int
+
+// CompareOptions are options for CompareWithOptions
+type CompareOptions struct {
+	// Collation is used to compare String values, BinaryCollation is used by default
+	Collation Collation
+}
+
+var (
+	// BinaryCollation compares strings byte-wise
+	BinaryCollation Collation = strings.Compare
+	// CaseInsensitiveCollation compares strings rune-wise, using simple Unicode case folding
+	CaseInsensitiveCollation Collation = compareFold
+)
+
+// Compare returns an integer comparing two nodes: 0 if a == b, -1 if a < b, and +1 if a > b.
+//
+// Nodes of different types are ordered by their type:
+//
+// 	null < numeric < string < bool < array < object
+//
+// Nodes of the same type are compared by value:
+//
+// 	Bool    false < true
+// 	Numeric by value, NaN is lesser than any other number and equal to NaN
+// 	String  by Collation, byte-wise by default
+// 	Array   element by element, shorter array is lesser, if it is a prefix of another
+// 	Object  as sorted arrays of key-value pairs: keys are compared byte-wise, then values
+//
+func Compare(a, b *Node) (int, error) {
+	return CompareWithOptions(a, b, CompareOptions{})
+}
+
+// CompareWithOptions returns an integer comparing two nodes with the given options. Check Compare for the details.
+func CompareWithOptions(a, b *Node, options CompareOptions) (result int, err error) {
+	if a == nil || b == nil {
+		return 0, errorUnparsed()
+	}
+	if a.Type() != b.Type() {
+		return compareInts(int(a.Type()), int(b.Type())), nil
+	}
+	switch a.Type() {
+	case Null:
+		return 0, nil
+	case Bool:
+		lnum, rnum, err := _bools(a, b)
+		if err != nil {
+			return 0, err
+		}
+		if lnum == rnum {
+			return 0, nil
+		}
+		if rnum {
+			return -1, nil
+		}
+		return 1, nil
+	case Numeric:
+		lnum, rnum, err := _floats(a, b)
+		if err != nil {
+			return 0, err
+		}
+		if lnan, rnan := math.IsNaN(lnum), math.IsNaN(rnum); lnan || rnan {
+			return compareInts(boolInt(!lnan), boolInt(!rnan)), nil
+		}
+		if lnum < rnum {
+			return -1, nil
+		}
+		if lnum > rnum {
+			return 1, nil
+		}
+		return 0, nil
+	case String:
+		lnum, rnum, err := _strings(a, b)
+		if err != nil {
+			return 0, err
+		}
+		collation := options.Collation
+		if collation == nil {
+			collation = BinaryCollation
+		}
+		return compareInts(collation(lnum, rnum), 0), nil
+	case Array:
+		lnum, rnum, err := _arrays(a, b)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < len(lnum) && i < len(rnum); i++ {
+			if result, err = CompareWithOptions(lnum[i], rnum[i], options); err != nil || result != 0 {
+				return result, err
+			}
+		}
+		return compareInts(len(lnum), len(rnum)), nil
+	case Object:
+		lkeys, rkeys := a.Keys(), b.Keys()
+		sort.Strings(lkeys)
+		sort.Strings(rkeys)
+		for i := 0; i < len(lkeys) && i < len(rkeys); i++ {
+			if result = strings.Compare(lkeys[i], rkeys[i]); result != 0 {
+				return result, nil
+			}
+			if result, err = CompareWithOptions(a.members[lkeys[i]], b.members[rkeys[i]], options); err != nil || result != 0 {
+				return result, err
+			}
+		}
+		return compareInts(len(lkeys), len(rkeys)), nil
+	}
+	return 0, errorType()
+}
+
+// CompareOperations returns script operations `<`, `<=`, `>` and `>=`, based on CompareWithOptions.
+// By default these operations compare only values of the same type, use AddOperation to replace them:
+//
+//	for alias, operation := range ajson.CompareOperations(ajson.CompareOptions{Collation: ajson.CaseInsensitiveCollation}) {
+//		ajson.AddOperation(alias, 3, false, operation)
+//	}
+//
+func CompareOperations(options CompareOptions) map[string]Operation {
+	operation := func(name string, fn func(result int) bool) Operation {
+		return func(left *Node, right *Node) (result *Node, err error) {
+			res, err := CompareWithOptions(left, right, options)
+			if err != nil {
+				return nil, err
+			}
+			return valueNode(nil, name, Bool, fn(res)), nil
+		}
+	}
+	return map[string]Operation{
+		"<":  operation("le", func(result int) bool { return result < 0 }),
+		"<=": operation("leq", func(result int) bool { return result <= 0 }),
+		">":  operation("ge", func(result int) bool { return result > 0 }),
+		">=": operation("geq", func(result int) bool { return result >= 0 }),
+	}
+}
+
+func boolInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareFold compares strings rune by rune, each rune is reduced to the smallest rune of its case folding orbit
+func compareFold(a, b string) int {
+	for a != "" && b != "" {
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			if result := compareInts(int(foldRune(ra)), int(foldRune(rb))); result != 0 {
+				return result
+			}
+		}
+		a, b = a[sa:], b[sb:]
+	}
+	return compareInts(len(a), len(b))
+}
+
+func foldRune(r rune) rune {
+	result := r
+	for current := unicode.SimpleFold(r); current != r; current = unicode.SimpleFold(current) {
+		if current < result {
+			result = current
+		}
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+package ajson
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		expected int
+	}{
+		{name: "null", left: `null`, right: `null`, expected: 0},
+		{name: "null<numeric", left: `null`, right: `0`, expected: -1},
+		{name: "numeric<string", left: `100`, right: `"1"`, expected: -1},
+		{name: "string<bool", left: `"z"`, right: `false`, expected: -1},
+		{name: "bool<array", left: `true`, right: `[]`, expected: -1},
+		{name: "array<object", left: `[1]`, right: `{}`, expected: -1},
+		{name: "object>null", left: `{}`, right: `null`, expected: 1},
+		{name: "false<true", left: `false`, right: `true`, expected: -1},
+		{name: "true>false", left: `true`, right: `false`, expected: 1},
+		{name: "true=true", left: `true`, right: `true`, expected: 0},
+		{name: "1<2", left: `1`, right: `2`, expected: -1},
+		{name: "2>1", left: `2`, right: `1`, expected: 1},
+		{name: "1=1.0", left: `1`, right: `1.0`, expected: 0},
+		{name: "a<b", left: `"a"`, right: `"b"`, expected: -1},
+		{name: "B<a", left: `"B"`, right: `"a"`, expected: -1},
+		{name: "ab>a", left: `"ab"`, right: `"a"`, expected: 1},
+		{name: "[1,2]<[1,3]", left: `[1,2]`, right: `[1,3]`, expected: -1},
+		{name: "[1,2]>[1]", left: `[1,2]`, right: `[1]`, expected: 1},
+		{name: "[1,2]=[1,2]", left: `[1,2]`, right: `[1,2]`, expected: 0},
+		{name: "[1,null]>[1,true]", left: `[2,null]`, right: `[1,true]`, expected: 1},
+		{name: "{a:1}<{b:0}", left: `{"a":1}`, right: `{"b":0}`, expected: -1},
+		{name: "{a:1}<{a:2}", left: `{"a":1}`, right: `{"a":2}`, expected: -1},
+		{name: "{a:1,b:2}={b:2,a:1}", left: `{"a":1,"b":2}`, right: `{"b":2,"a":1}`, expected: 0},
+		{name: "{a:1,b:2}>{a:1}", left: `{"a":1,"b":2}`, right: `{"a":1}`, expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Compare(Must(Unmarshal([]byte(test.left))), Must(Unmarshal([]byte(test.right))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result != test.expected {
+				t.Errorf("Wrong result: %d, expected: %d", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestCompare_error(t *testing.T) {
+	broken := &Node{_type: Numeric, borders: [2]int{0, 1}, data: &[]byte{'x'}}
+	tests := []struct {
+		name        string
+		left, right *Node
+	}{
+		{name: "nil left", left: nil, right: NullNode("")},
+		{name: "nil right", left: NullNode(""), right: nil},
+		{name: "broken", left: broken, right: NumericNode("", 1)},
+		{name: "broken in array", left: ArrayNode("", []*Node{broken}), right: ArrayNode("", []*Node{NumericNode("", 1)})},
+		{name: "broken in object", left: ObjectNode("", map[string]*Node{"a": broken}), right: ObjectNode("", map[string]*Node{"a": NumericNode("", 1)})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Compare(test.left, test.right); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestCompareWithOptions_collation(t *testing.T) {
+	tests := []struct {
+		name      string
+		left      string
+		right     string
+		collation Collation
+		expected  int
+	}{
+		{name: "binary", left: "B", right: "a", collation: BinaryCollation, expected: -1},
+		{name: "case insensitive", left: "B", right: "a", collation: CaseInsensitiveCollation, expected: 1},
+		{name: "case insensitive: simple folding only", left: "Straße", right: "STRASSE", collation: CaseInsensitiveCollation, expected: 1},
+		{name: "case insensitive unicode", left: "ΣΊΣΥΦΟΣ", right: "σίσυφος", collation: CaseInsensitiveCollation, expected: 0},
+		{name: "case insensitive prefix", left: "abc", right: "AB", collation: CaseInsensitiveCollation, expected: 1},
+		{name: "custom", left: "10", right: "9", collation: func(a, b string) int { return len(a) - len(b) }, expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := CompareWithOptions(StringNode("", test.left), StringNode("", test.right), CompareOptions{Collation: test.collation})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result != test.expected {
+				t.Errorf("Wrong result: %d, expected: %d", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestCompare_sort(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[{"a":1}, "b", [2], null, true, 3, "A", -1, false, [1, 2], {}]`)))
+	nodes := root.MustArray()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		result, _ := CompareWithOptions(nodes[i], nodes[j], CompareOptions{Collation: CaseInsensitiveCollation})
+		return result < 0
+	})
+	result := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.String())
+	}
+	expected := `null -1 3 "A" "b" false true [1, 2] [2] {} {"a":1}`
+	if strings.Join(result, " ") != expected {
+		t.Errorf("Wrong order: %s", strings.Join(result, " "))
+	}
+}
+
+func TestCompare_NaN(t *testing.T) {
+	nan, inf := NumericNode("", math.NaN()), NumericNode("", math.Inf(-1))
+	tests := []struct {
+		name        string
+		left, right *Node
+		expected    int
+	}{
+		{name: "NaN=NaN", left: nan, right: NumericNode("", math.NaN()), expected: 0},
+		{name: "NaN<-Inf", left: nan, right: inf, expected: -1},
+		{name: "-Inf>NaN", left: inf, right: nan, expected: 1},
+		{name: "1>NaN", left: NumericNode("", 1), right: nan, expected: 1},
+		{name: "NaN>null", left: nan, right: NullNode(""), expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Compare(test.left, test.right)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result != test.expected {
+				t.Errorf("Wrong result: %d, expected: %d", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestCompareOperations(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[{"v": 1}, {"v": "abc"}, {"v": 20}, {"v": null}, {"v": "ABD"}]`)))
+	expr, err := newBuffer([]byte(`@.v < "abd"`)).rpn()
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	backup := make(map[string]Operation)
+	for _, alias := range []string{"<", "<=", ">", ">="} {
+		backup[alias] = operations[alias]
+	}
+	defer func() {
+		for alias, operation := range backup {
+			operations[alias] = operation
+		}
+	}()
+	tests := []struct {
+		name     string
+		options  *CompareOptions
+		expected string
+	}{
+		{name: "default", expected: `{"v": "abc"} {"v": "ABD"}`},
+		{name: "binary", options: &CompareOptions{}, expected: `{"v": 1} {"v": "abc"} {"v": 20} {"v": null} {"v": "ABD"}`},
+		{name: "case insensitive", options: &CompareOptions{Collation: CaseInsensitiveCollation}, expected: `{"v": 1} {"v": "abc"} {"v": 20} {"v": null}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for alias, operation := range backup {
+				operations[alias] = operation
+			}
+			if test.options != nil {
+				for alias, operation := range CompareOperations(*test.options) {
+					AddOperation(alias, 3, false, operation)
+				}
+			}
+			result := make([]string, 0)
+			for _, node := range root.MustArray() {
+				value, err := eval(node, expr, "")
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+					return
+				}
+				if value.MustBool() {
+					result = append(result, node.String())
+				}
+			}
+			if strings.Join(result, " ") != test.expected {
+				t.Errorf("Wrong result: %s", strings.Join(result, " "))
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package ajson
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
+// CSVOptions are options of the CSV conversion
+type CSVOptions struct {
+	// Comma is the field delimiter, ',' by default. Use '\t' for TSV.
+	Comma rune
+	// Separator joins keys of nested objects in column headers, "." by default. The separator and '\' in keys are
+	// escaped with '\', as Flatten does.
+	Separator string
+	// Strings disables the type inference in FromCSV: all values become strings, empty values as well
+	Strings bool
+}
+
+// ToCSV converts the array of objects into the CSV table: the header contains keys of all objects in order of
+// appearance, keys of nested objects are joined with the separator. Missing keys and null values become empty cells,
+// arrays and empty objects are written as JSON. The single object is converted as the table with one row.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`[{"id": 1, "user": {"name": "a"}}, {"id": 2, "tags": ["b"]}]`)))
+//	result, _ := ToCSV(root, CSVOptions{})
+//	// string(result) == "id,user.name,tags\n1,a,\n2,,\"[\"\"b\"\"]\"\n"
+//
+func ToCSV(node *Node, options CSVOptions) ([]byte, error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	}
+	rows := []*Node{node}
+	if node.IsArray() {
+		rows = node.children
+	}
+	separator := options.csvSeparator()
+	columns := make([]string, 0)
+	seen := make(map[string]bool)
+	values := make([]map[string]string, 0, len(rows))
+	for i, row := range rows {
+		if row == nil || !row.IsObject() {
+			return nil, errorRequest("element %d is not an object", i)
+		}
+		value := make(map[string]string)
+		if err := csvFlatten(row, "", separator, value, func(column string) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	buf := new(bytes.Buffer)
+	writer := csv.NewWriter(buf)
+	if options.Comma != 0 {
+		writer.Comma = options.Comma
+	}
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(columns))
+	for _, value := range values {
+		for i, column := range columns {
+			record[i] = value[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FromCSV converts the CSV table into the array of objects, the first row is the header. Columns with the separator
+// in the header become nested objects. Values are inferred: empty values become null, JSON numbers, booleans,
+// arrays and objects are parsed, other values are strings.
+// Example:
+//
+//	root, _ := FromCSV([]byte("id,user.name,tags\n1,a,\n2,,\"[\"\"b\"\"]\"\n"), CSVOptions{})
+//	// root.String() == `[{"id":1,"user":{"name":"a"},"tags":null},{"id":2,"user":{"name":null},"tags":["b"]}]`
+//
+func FromCSV(data []byte, options CSVOptions) (*Node, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	result := ArrayNode("", nil)
+	if len(records) == 0 {
+		return result, nil
+	}
+	separator := options.csvSeparator()
+	seen := make(map[string]bool, len(records[0]))
+	for _, column := range records[0] {
+		if seen[column] {
+			return nil, errorRequest("duplicate column %q", column)
+		}
+		seen[column] = true
+	}
+	for _, record := range records[1:] {
+		row := ObjectNode("", nil)
+		for i, column := range records[0] {
+			if err = csvSet(row, column, separator, options.csvValue(record[i])); err != nil {
+				return nil, err
+			}
+		}
+		if err = result.AppendArray(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// csvFlatten collects values of the object by columns, column is called for every column in order of appearance
+func csvFlatten(node *Node, prefix string, separator string, value map[string]string, column func(string)) error {
+	for _, child := range node.children {
+		if child == nil {
+			return errorUnparsed()
+		}
+		name := prefix + flattenEscape(child.Key(), separator)
+		if child.IsObject() && len(child.children) != 0 {
+			if err := csvFlatten(child, name+separator, separator, value, column); err != nil {
+				return err
+			}
+			continue
+		}
+		column(name)
+		cell, err := scalarText(child)
+		if err != nil {
+			return err
+		}
+		value[name] = cell
+	}
+	return nil
+}
+
+// scalarText returns the text of the value: strings as is, numbers as written in the source, containers as JSON
+func scalarText(node *Node) (string, error) {
+	switch node.Type() {
+	case Null:
+		return "", nil
+	case String:
+		return node.GetString()
+	case Numeric:
+		if source := node.Source(); source != nil {
+			return string(source), nil
+		}
+		value, err := node.GetNumeric()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case Bool:
+		value, err := node.GetBool()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(value), nil
+	}
+	result, err := MarshalWithOptions(node, MarshalOptions{DisableHTMLEscape: true})
+	return string(result), err
+}
+
+// csvSet sets the value of the column, keys of the column are split by the not escaped separator to create nested
+// objects
+func csvSet(node *Node, column string, separator string, value *Node) error {
+	path := flattenSplit(column, separator)
+	for _, key := range path[:len(path)-1] {
+		child, ok := node.members[key]
+		if !ok {
+			child = ObjectNode("", nil)
+			if err := node.AppendObject(key, child); err != nil {
+				return err
+			}
+		} else if !child.IsObject() {
+			return errorRequest("column %q conflicts with other columns", column)
+		}
+		node = child
+	}
+	key := path[len(path)-1]
+	if node.HasKey(key) {
+		return errorRequest("column %q conflicts with other columns", column)
+	}
+	return node.AppendObject(key, value)
+}
+
+// csvValue returns the node of the cell value
+func (o CSVOptions) csvValue(cell string) *Node {
+	if o.Strings {
+		return StringNode("", cell)
+	}
+	if cell == "" {
+		return NullNode("")
+	}
+	if node, err := Unmarshal([]byte(cell)); err == nil && node.Type() != String {
+		return node
+	}
+	return StringNode("", cell)
+}
+
+func (o CSVOptions) csvSeparator() string {
+	if o.Separator == "" {
+		return "."
+	}
+	return o.Separator
+}
@@ -0,0 +1,160 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  CSVOptions
+		expected string
+	}{
+		{name: "empty", input: `[]`, expected: "\n"},
+		{name: "object", input: `{"a": 1, "b": "x"}`, expected: "a,b\n1,x\n"},
+		{
+			name:     "heterogeneous",
+			input:    `[{"id": 1, "name": "a"}, {"id": 2, "note": "b, \"c\""}, {"name": null}]`,
+			expected: "id,name,note\n1,a,\n2,,\"b, \"\"c\"\"\"\n,,\n",
+		},
+		{
+			name:     "nested",
+			input:    `[{"id": 1, "user": {"name": "a", "address": {"city": "b"}}, "tags": ["x", "<y>"], "meta": {}}]`,
+			expected: "id,user.name,user.address.city,tags,meta\n1,a,b,\"[\"\"x\"\", \"\"<y>\"\"]\",{}\n",
+		},
+		{
+			name:     "values",
+			input:    `[{"a": 1.50, "b": 1e3, "c": true, "d": false, "e": null, "f": "line\nbreak"}]`,
+			expected: "a,b,c,d,e,f\n1.50,1e3,true,false,,\"line\nbreak\"\n",
+		},
+		{
+			name:     "tsv",
+			input:    `[{"a": {"b": 1}, "c": "d"}]`,
+			options:  CSVOptions{Comma: '\t', Separator: "/"},
+			expected: "a/b\tc\n1\td\n",
+		},
+		{
+			name:     "escaped",
+			input:    `[{"a.b": 1, "a": {"b": 2}, "c\\d": 3}]`,
+			expected: "a\\.b,a.b,c\\\\d\n1,2,3\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ToCSV(Must(Unmarshal([]byte(test.input))), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestToCSV_changed(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[{"a": 1}]`)))
+	_ = root.MustIndex(0).MustKey("a").SetNumeric(1234567)
+	_ = root.MustIndex(0).AppendObject("b", NumericNode("", 0.5))
+	result, err := ToCSV(root, CSVOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if string(result) != "a,b\n1234567,0.5\n" {
+		t.Errorf("Wrong result: %s", result)
+	}
+}
+
+func TestToCSV_errors(t *testing.T) {
+	if _, err := ToCSV(nil, CSVOptions{}); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+	if _, err := ToCSV(Must(Unmarshal([]byte(`[{}, 1]`))), CSVOptions{}); err == nil {
+		t.Errorf("Expected error for numeric")
+	} else if err.Error() != "wrong request: element 1 is not an object" {
+		t.Errorf("Wrong error: %s", err)
+	}
+	if _, err := ToCSV(Must(Unmarshal([]byte(`"a"`))), CSVOptions{}); err == nil {
+		t.Errorf("Expected error for string")
+	}
+}
+
+func TestFromCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  CSVOptions
+		expected string
+	}{
+		{name: "empty", input: ``, expected: `[]`},
+		{name: "header", input: "a,b\n", expected: `[]`},
+		{
+			name:     "values",
+			input:    "a,b,c,d,e,f,g\n1.5,true,,\"[1, 2]\",007,null,\"x, y\"\n",
+			expected: `[{"a":1.5,"b":true,"c":null,"d":[1, 2],"e":"007","f":null,"g":"x, y"}]`,
+		},
+		{
+			name:     "nested",
+			input:    "id,user.name,user.address.city\n1,a,b\n2,,\n",
+			expected: `[{"id":1,"user":{"name":"a","address":{"city":"b"}}},{"id":2,"user":{"name":null,"address":{"city":null}}}]`,
+		},
+		{
+			name:     "strings",
+			input:    "a\tb/c\n1\t\n",
+			options:  CSVOptions{Comma: '\t', Separator: "/", Strings: true},
+			expected: `[{"a":"1","b":{"c":""}}]`,
+		},
+		{
+			name:     "escaped",
+			input:    "a\\.b,a.b,c\\\\d\n1,2,3\n",
+			expected: `[{"a.b":1,"a":{"b":2},"c\\d":3}]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := FromCSV([]byte(test.input), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result := root.String(); result != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestFromCSV_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "duplicate", input: "a,a\n1,2\n", err: `wrong request: duplicate column "a"`},
+		{name: "conflict", input: "a,a.b\n1,2\n", err: `wrong request: column "a.b" conflicts with other columns`},
+		{name: "conflict nested", input: "a.b,a\n1,2\n", err: `wrong request: column "a" conflicts with other columns`},
+		{name: "fields", input: "a,b\n1\n", err: "record on line 2: wrong number of fields"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromCSV([]byte(test.input), CSVOptions{})
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestCSV_roundTrip(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[{"id": 1, "user": {"name": "a, b"}, "tags": ["x"]}, {"id": 2, "user": {"name": "c\nd"}, "tags": []}]`)))
+	data, err := ToCSV(root, CSVOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	result, err := FromCSV(data, CSVOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if ok, err := result.Eq(root); err != nil || !ok {
+		t.Errorf("Wrong result: %s, expected: %s", result, root)
+	}
+}
@@ -1,6 +1,8 @@
 package ajson
 
 import (
+	"io"
+
 	. "github.com/spyzhov/ajson/internal"
 )
 
@@ -17,26 +19,104 @@ const (
 	bc States = -7 /* bracket close   */
 	cc States = -8 /* curly br. close */
 	ec States = -9 /* curly br. empty */
+
+	/*
+		The relaxed mode action codes.
+	*/
+	rs States = -10 /* relaxed string  */
+	rk States = -11 /* relaxed key     */
+	rn States = -12 /* relaxed numeric */
 )
 
+// DuplicateKeys is a policy of handling repeated keys of the object
+type DuplicateKeys int
+
+const (
+	// DuplicateKeysLast keeps the last value of the repeated key, on the position of the first one.
+	// Keys are not checked, so Marshal of the unchanged object returns its source with all repetitions.
+	DuplicateKeysLast DuplicateKeys = iota
+	// DuplicateKeysFirst keeps the first value of the repeated key, next values are ignored.
+	DuplicateKeysFirst
+	// DuplicateKeysReject returns an error of type DuplicateKey on the repeated key
+	DuplicateKeysReject
+	// DuplicateKeysCollect keeps all values of the repeated key as an array: `{"a":1,"a":2}` became `{"a":[1,2]}`.
+	// Keys without repetitions are not affected.
+	DuplicateKeysCollect
+)
+
+// Options are options for UnmarshalWithOptions
+type Options struct {
+	// DuplicateKeys is a policy of handling repeated keys of the object, DuplicateKeysLast by default
+	DuplicateKeys DuplicateKeys
+	// MaxDepth is the maximum nesting level of arrays and objects, error of type MaxDepthExceeded will be returned.
+	// The root container has the depth 1.
+	MaxDepth int
+	// MaxBytes is the maximum size of the data, error of type MaxBytesExceeded will be returned
+	MaxBytes int
+	// MaxStringLen is the maximum length in bytes of the encoded string or key, without quotes.
+	// Error of type MaxStringLenExceeded will be returned.
+	MaxStringLen int
+	// MaxKeys is the maximum count of keys of the single object, error of type MaxKeysExceeded will be returned
+	MaxKeys int
+	// MaxArrayLen is the maximum count of elements of the single array, error of type MaxArrayLenExceeded will be returned
+	MaxArrayLen int
+	// Relaxed enables JSON5 syntax: comments, trailing commas, single-quoted strings, unquoted keys,
+	// hexadecimal numbers, numbers with leading plus sign or leading or trailing decimal point, Infinity and NaN.
+	// Nodes, which source is not a valid JSON, are marked as changed, so Marshal returns a valid JSON for them,
+	// except Infinity and NaN values, that have no JSON representation.
+	Relaxed bool
+}
+
 // Unmarshal parses the JSON-encoded data and return the root node of struct.
 //
 // Doesn't calculate values, just type of stored value. It will store link to the data, on all life long.
 func Unmarshal(data []byte) (root *Node, err error) {
+	return UnmarshalWithOptions(data, Options{})
+}
+
+// UnmarshalWithOptions parses the JSON-encoded data with the given options and return the root node of struct.
+// Example:
+//
+//	root, err := UnmarshalWithOptions([]byte(`{"a":1,"a":2}`), Options{DuplicateKeys: DuplicateKeysReject})
+//	// err.Error() == "duplicate key 'a' at 7"
+//
+// Zero value of any limit means that value is not limited:
+//
+//	root, err := UnmarshalWithOptions([]byte(`[[[]]]`), Options{MaxDepth: 2})
+//	// err.Error() == "max depth 2 exceeded at 2"
+//
+func UnmarshalWithOptions(data []byte, options Options) (root *Node, err error) {
 	buf := newBuffer(data)
 	var (
-		state   States
-		key     *string
-		current *Node
+		state     States
+		key       *string
+		current   *Node
+		duplicate *Node
+		collected map[*Node][]*Node
+		depth     int
 	)
 
-	_, err = buf.first()
-	if err != nil {
+	if options.MaxBytes > 0 && len(data) > options.MaxBytes {
+		return nil, errorLimit(MaxBytesExceeded, options.MaxBytes, options.MaxBytes)
+	}
+
+	if options.Relaxed {
+		_, err = buf.skipRelaxed()
+	} else {
+		_, err = buf.first()
+	}
+	if err == io.EOF {
 		return nil, buf.errorEOF()
+	} else if err != nil {
+		return nil, err
 	}
 
 	for {
-		state = buf.getState()
+		if options.Relaxed {
+			state = buf.relaxedState(current)
+		} else {
+			state = buf.getState()
+		}
 		if state == __ {
 			return nil, buf.errorSymbol()
 		}
@@ -47,7 +127,11 @@ func Unmarshal(data []byte) (root *Node, err error) {
 			case ST:
 				if current != nil && current.IsObject() && key == nil {
 					// Detected: Key
-					key, err = getString(buf)
+					start := buf.index
+					key, err = getString(buf, options)
+					if err == nil {
+						duplicate, err = options.key(current, start, *key)
+					}
 					buf.state = CO
 				} else {
 					// Detected: String
@@ -57,6 +141,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 					}
 					err = buf.string(quotes, false)
 					current.borders[1] = buf.index + 1
+					if err == nil {
+						err = options.stringLen(current.borders[0], buf.index)
+					}
 					buf.state = OK
 					if current.parent != nil {
 						current = current.parent
@@ -112,6 +199,7 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				fallthrough
 			case cc: /* } */
 				if current != nil && current.IsObject() && !current.ready() {
+					depth--
 					current.borders[1] = buf.index + 1
 					if current.parent != nil {
 						current = current.parent
@@ -122,6 +210,7 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				buf.state = OK
 			case bc: /* ] */
 				if current != nil && current.IsArray() && !current.ready() {
+					depth--
 					current.borders[1] = buf.index + 1
 					if current.parent != nil {
 						current = current.parent
@@ -131,9 +220,15 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				}
 				buf.state = OK
 			case co: /* { */
+				if depth++; options.MaxDepth > 0 && depth > options.MaxDepth {
+					return nil, errorLimit(MaxDepthExceeded, buf.index, options.MaxDepth)
+				}
 				current, err = newNode(current, buf, Object, &key)
 				buf.state = OB
 			case bo: /* [ */
+				if depth++; options.MaxDepth > 0 && depth > options.MaxDepth {
+					return nil, errorLimit(MaxDepthExceeded, buf.index, options.MaxDepth)
+				}
 				current, err = newNode(current, buf, Array, &key)
 				buf.state = AR
 			case cm: /* , */
@@ -143,6 +238,9 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				if current.IsObject() {
 					buf.state = KE
 				} else if current.IsArray() {
+					if options.MaxArrayLen > 0 && len(current.children) >= options.MaxArrayLen {
+						return nil, errorLimit(MaxArrayLenExceeded, buf.index, options.MaxArrayLen)
+					}
 					buf.state = VA
 				} else {
 					err = buf.errorSymbol()
@@ -153,6 +251,48 @@ func Unmarshal(data []byte) (root *Node, err error) {
 				} else {
 					buf.state = VA
 				}
+			case rs: /* string in relaxed mode */
+				current, err = newNode(current, buf, String, &key)
+				if err != nil {
+					break
+				}
+				err = buf.relaxedString()
+				current.borders[1] = buf.index + 1
+				if err == nil {
+					err = options.stringLen(current.borders[0], buf.index)
+				}
+				if err == nil {
+					err = relaxedValue(current)
+				}
+				buf.state = OK
+				if current.parent != nil {
+					current = current.parent
+				}
+			case rk: /* key in relaxed mode */
+				var strict bool
+				start := buf.index
+				key, strict, err = relaxedKey(buf, options)
+				if err == nil {
+					duplicate, err = options.key(current, start, *key)
+				}
+				if !strict {
+					current.mark()
+				}
+				buf.state = CO
+			case rn: /* numeric in relaxed mode */
+				current, err = newNode(current, buf, Numeric, &key)
+				if err != nil {
+					break
+				}
+				err = buf.relaxedNumeric()
+				current.borders[1] = buf.index + 1
+				if err == nil {
+					err = relaxedValue(current)
+				}
+				buf.state = OK
+				if current.parent != nil {
+					current = current.parent
+				}
 			default: /* syntax error */
 				err = buf.errorSymbol()
 			}
@@ -161,12 +301,36 @@ func Unmarshal(data []byte) (root *Node, err error) {
 		if err != nil {
 			return
 		}
+		if duplicate != nil && key == nil {
+			// value of the repeated key was attached instead of the previous one
+			parent := duplicate.parent
+			value := parent.members[*duplicate.key]
+			parent.setMember(*duplicate.key, duplicate)
+			parent.mark()
+			if options.DuplicateKeys == DuplicateKeysCollect {
+				if collected == nil {
+					collected = make(map[*Node][]*Node)
+				}
+				collected[duplicate] = append(collected[duplicate], value)
+			}
+			duplicate = nil
+		}
 		if buf.step() != nil {
 			break
 		}
-		if _, err = buf.first(); err != nil {
+		if options.Relaxed {
+			var extra bool
+			if extra, err = buf.skipRelaxed(); extra && current != nil && current.isContainer() {
+				current.mark()
+			}
+		} else {
+			_, err = buf.first()
+		}
+		if err == io.EOF {
 			err = nil
 			break
+		} else if err != nil {
+			return nil, err
 		}
 	}
 
@@ -179,10 +343,48 @@ func Unmarshal(data []byte) (root *Node, err error) {
 			root = nil
 		}
 	}
+	for first, values := range collected {
+		collect(first, values)
+	}
 
 	return
 }
 
+// key checks the key of the current object, returns the previous value of the key, if it should be restored
+func (o Options) key(current *Node, start int, key string) (*Node, error) {
+	previous, ok := current.members[key]
+	if !ok && o.MaxKeys > 0 && len(current.children) >= o.MaxKeys {
+		return nil, errorLimit(MaxKeysExceeded, start, o.MaxKeys)
+	}
+	if !ok || o.DuplicateKeys == DuplicateKeysLast {
+		return nil, nil
+	}
+	if o.DuplicateKeys == DuplicateKeysReject {
+		return nil, errorDuplicate(start, key)
+	}
+	return previous, nil
+}
+
+// stringLen checks the length of the string between quotes on the given positions
+func (o Options) stringLen(start, end int) error {
+	if o.MaxStringLen > 0 && end-start-1 > o.MaxStringLen {
+		return errorLimit(MaxStringLenExceeded, start, o.MaxStringLen)
+	}
+	return nil
+}
+
+// collect replaces the first value of the repeated key with the array of all its values
+func collect(first *Node, values []*Node) {
+	parent, key := first.parent, *first.key
+	array := ArrayNode(key, append([]*Node{first}, values...))
+	for _, value := range array.children {
+		value.key = nil
+	}
+	array.parent = parent
+	parent.setMember(key, array)
+	parent.mark()
+}
+
 // UnmarshalSafe do the same thing as Unmarshal, but copy data to the local variable, to make it editable.
 func UnmarshalSafe(data []byte) (root *Node, err error) {
 	var safe []byte
@@ -198,12 +400,16 @@ func Must(root *Node, err error) *Node {
 	return root
 }
 
-func getString(b *buffer) (*string, error) {
+// getString returns unquoted string, if its length is allowed by options
+func getString(b *buffer, options Options) (*string, error) {
 	start := b.index
 	err := b.string(quotes, false)
 	if err != nil {
 		return nil, err
 	}
+	if err = options.stringLen(start, b.index); err != nil {
+		return nil, err
+	}
 	value, ok := unquote(b.data[start:b.index+1], quotes)
 	if !ok {
 		return nil, errorSymbol(b)
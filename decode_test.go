@@ -436,6 +436,134 @@ func TestUnmarshal_Must(t *testing.T) {
 	}
 }
 
+func TestUnmarshalWithOptions_DuplicateKeys(t *testing.T) {
+	input := []byte(`{"a": 1, "b": {"c": true, "c": false}, "a": [2], "d": null, "a": {"e": 3, "e": 4}}`)
+	tests := []struct {
+		name     string
+		policy   DuplicateKeys
+		expected string
+	}{
+		{name: "last", policy: DuplicateKeysLast, expected: `{"a":{"e":4},"b":{"c":false},"d":null}`},
+		{name: "first", policy: DuplicateKeysFirst, expected: `{"a":1,"b":{"c":true},"d":null}`},
+		{name: "collect", policy: DuplicateKeysCollect, expected: `{"a":[1,[2],{"e":[3,4]}],"b":{"c":[true,false]},"d":null}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions(input, Options{DuplicateKeys: test.policy})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if ok, err := root.Eq(Must(Unmarshal([]byte(test.expected)))); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if !ok {
+				t.Errorf("Wrong result: %v, expected: %s", root.MustObject(), test.expected)
+			}
+			if test.policy != DuplicateKeysLast {
+				result, err := Marshal(root)
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				} else if string(result) != test.expected {
+					t.Errorf("Wrong marshal result: %s, expected: %s", result, test.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_DuplicateKeysCollect(t *testing.T) {
+	root := Must(UnmarshalWithOptions([]byte(`{"x": {"a": 1, "a": 2}}`), Options{DuplicateKeys: DuplicateKeysCollect}))
+	nodes, err := root.JSONPath("$.x.a[1]")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if len(nodes) != 1 || nodes[0].MustNumeric() != 2 {
+		t.Errorf("Wrong result: %v", nodes)
+	} else if nodes[0].Path() != "$['x']['a'][1]" {
+		t.Errorf("Wrong path: %s", nodes[0].Path())
+	}
+	if keys := root.MustKey("x").Keys(); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Wrong keys: %v", keys)
+	}
+}
+
+func TestUnmarshalWithOptions_DuplicateKeysReject(t *testing.T) {
+	tests := []struct {
+		input string
+		index int
+		key   string
+	}{
+		{input: `{"a":1,"a":2}`, index: 7, key: "a"},
+		{input: `[{"a":1}, {"b":{"c":1, "\u0063":2}}]`, index: 23, key: "c"},
+		{input: `{"a":{"b":1},"a":{"b":1}}`, index: 13, key: "a"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.input), Options{DuplicateKeys: DuplicateKeysReject})
+			if err == nil {
+				t.Errorf("Expected error, got: %s", root)
+				return
+			}
+			if e, ok := err.(Error); !ok || e.Type != DuplicateKey || e.Index != test.index || e.Message != test.key {
+				t.Errorf("Wrong error: %#v", err)
+			}
+		})
+	}
+	if _, err := UnmarshalWithOptions([]byte(`[{"a":1},{"a":1}]`), Options{DuplicateKeys: DuplicateKeysReject}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestUnmarshalWithOptions_limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		_type   ErrorType
+		index   int
+	}{
+		{name: "MaxDepth", input: `[[[1]]]`, options: Options{MaxDepth: 2}, _type: MaxDepthExceeded, index: 2},
+		{name: "MaxDepth object", input: `{"a":{"b":{}}}`, options: Options{MaxDepth: 2}, _type: MaxDepthExceeded, index: 10},
+		{name: "MaxDepth siblings", input: `[[1],[2],[[3]]]`, options: Options{MaxDepth: 2}, _type: MaxDepthExceeded, index: 10},
+		{name: "MaxBytes", input: `[1, 2, 3]`, options: Options{MaxBytes: 8}, _type: MaxBytesExceeded, index: 8},
+		{name: "MaxStringLen", input: `["abc", "abcd"]`, options: Options{MaxStringLen: 3}, _type: MaxStringLenExceeded, index: 8},
+		{name: "MaxStringLen escaped", input: `["\u0061"]`, options: Options{MaxStringLen: 3}, _type: MaxStringLenExceeded, index: 1},
+		{name: "MaxStringLen key", input: `{"abcd": 1}`, options: Options{MaxStringLen: 3}, _type: MaxStringLenExceeded, index: 1},
+		{name: "MaxKeys", input: `{"a": 1, "b": 2, "c": 3}`, options: Options{MaxKeys: 2}, _type: MaxKeysExceeded, index: 17},
+		{name: "MaxArrayLen", input: `[1, 2, 3]`, options: Options{MaxArrayLen: 2}, _type: MaxArrayLenExceeded, index: 5},
+		{name: "MaxArrayLen nested", input: `[[1, 2], [1, 2, 3]]`, options: Options{MaxArrayLen: 2}, _type: MaxArrayLenExceeded, index: 14},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.input), test.options)
+			if err == nil {
+				t.Errorf("Expected error, got: %s", root)
+				return
+			}
+			if e, ok := err.(Error); !ok || e.Type != test._type || e.Index != test.index {
+				t.Errorf("Wrong error: %#v", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_limitsValid(t *testing.T) {
+	options := Options{MaxDepth: 2, MaxBytes: 64, MaxStringLen: 3, MaxKeys: 2, MaxArrayLen: 2}
+	tests := []string{
+		`[[1, 2], [3]]`,
+		`{"a": [1, 2], "b": {"abc": "def"}}`,
+		`{"a": 1, "b": 2, "a": 3}`,
+		`"abc"`,
+		`[]`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if _, err := UnmarshalWithOptions([]byte(test), options); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		})
+	}
+}
+
 // Examples from https://json.org/example.html
 func TestUnmarshal(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,208 @@
+package ajson
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// ChangeKind is a kind of difference between two nodes
+type ChangeKind int
+
+const (
+	// Added means that value exists only in the second document
+	Added ChangeKind = iota
+	// Removed means that value exists only in the first document
+	Removed
+	// Changed means that value was changed, but the type is the same
+	Changed
+	// TypeChanged means that type of the value was changed
+	TypeChanged
+)
+
+// Change is a single difference between two nodes
+type Change struct {
+	// Path is a normalized JSONPath of the value, relative to the compared nodes
+	Path string
+	// Kind of the change
+	Kind ChangeKind
+	// Old value, nil for Added
+	Old *Node
+	// New value, nil for Removed
+	New *Node
+}
+
+// DiffOptions are options for DiffWithOptions
+type DiffOptions struct {
+	// IgnoreArrayOrder compares arrays as multisets: elements are matched with equal ones, regardless of their index
+	IgnoreArrayOrder bool
+	// FloatTolerance is the maximum absolute difference between numeric values to be treated as equal
+	FloatTolerance float64
+}
+
+// String is implementation of Stringer interface
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case TypeChanged:
+		return "type-changed"
+	}
+	return "unknown"
+}
+
+// Diff returns all structural differences between two nodes, sorted by keys and indexes.
+// Example:
+//
+//	changes, _ := Diff(Must(Unmarshal([]byte(`{"a":1,"b":2}`))), Must(Unmarshal([]byte(`{"a":3,"c":2}`))))
+//	// changes == []Change{
+//	// 	{Path: "$['a']", Kind: Changed, Old: 1, New: 3},
+//	// 	{Path: "$['b']", Kind: Removed, Old: 2},
+//	// 	{Path: "$['c']", Kind: Added, New: 2},
+//	// }
+//
+func Diff(a, b *Node) ([]Change, error) {
+	return DiffWithOptions(a, b, DiffOptions{})
+}
+
+// DiffWithOptions returns all structural differences between two nodes with the given options.
+func DiffWithOptions(a, b *Node, options DiffOptions) (result []Change, err error) {
+	if a == nil || b == nil {
+		return nil, errorUnparsed()
+	}
+	result = make([]Change, 0)
+	err = options.diff(&result, "$", a, b)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (o DiffOptions) diff(result *[]Change, path string, a, b *Node) error {
+	if a.Type() != b.Type() {
+		*result = append(*result, Change{Path: path, Kind: TypeChanged, Old: a, New: b})
+		return nil
+	}
+	switch a.Type() {
+	case Array:
+		if o.IgnoreArrayOrder {
+			return o.diffUnordered(result, path, a, b)
+		}
+		lnodes, rnodes, err := _arrays(a, b)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(lnodes) || i < len(rnodes); i++ {
+			current := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(rnodes):
+				*result = append(*result, Change{Path: current, Kind: Removed, Old: lnodes[i]})
+			case i >= len(lnodes):
+				*result = append(*result, Change{Path: current, Kind: Added, New: rnodes[i]})
+			default:
+				if err = o.diff(result, current, lnodes[i], rnodes[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case Object:
+		lnodes, rnodes, err := _objects(a, b)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(lnodes)+len(rnodes))
+		for key := range lnodes {
+			keys = append(keys, key)
+		}
+		for key := range rnodes {
+			if _, ok := lnodes[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			current := path + "['" + escapeJSONPathKey(key) + "']"
+			lnode, lok := lnodes[key]
+			rnode, rok := rnodes[key]
+			switch {
+			case !rok:
+				*result = append(*result, Change{Path: current, Kind: Removed, Old: lnode})
+			case !lok:
+				*result = append(*result, Change{Path: current, Kind: Added, New: rnode})
+			default:
+				if err = o.diff(result, current, lnode, rnode); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		equal, err := o.equal(a, b)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			*result = append(*result, Change{Path: path, Kind: Changed, Old: a, New: b})
+		}
+	}
+	return nil
+}
+
+// diffUnordered matches every element of the first array with the first unmatched equal element of the second one
+func (o DiffOptions) diffUnordered(result *[]Change, path string, a, b *Node) error {
+	lnodes, rnodes, err := _arrays(a, b)
+	if err != nil {
+		return err
+	}
+	matched := make([]bool, len(rnodes))
+	for i, lnode := range lnodes {
+		found := false
+		for j, rnode := range rnodes {
+			if matched[j] {
+				continue
+			}
+			found, err = o.equal(lnode, rnode)
+			if err != nil {
+				return err
+			}
+			if found {
+				matched[j] = true
+				break
+			}
+		}
+		if !found {
+			*result = append(*result, Change{Path: path + "[" + strconv.Itoa(i) + "]", Kind: Removed, Old: lnode})
+		}
+	}
+	for j, rnode := range rnodes {
+		if !matched[j] {
+			*result = append(*result, Change{Path: path + "[" + strconv.Itoa(j) + "]", Kind: Added, New: rnode})
+		}
+	}
+	return nil
+}
+
+// equal checks if nodes value are the same, with respect to the options
+func (o DiffOptions) equal(a, b *Node) (bool, error) {
+	if a.Type() != b.Type() {
+		return false, nil
+	}
+	switch a.Type() {
+	case Numeric:
+		lnum, rnum, err := _floats(a, b)
+		if err != nil {
+			return false, err
+		}
+		return math.Abs(lnum-rnum) <= o.FloatTolerance, nil
+	case Array, Object:
+		changes := make([]Change, 0)
+		if err := o.diff(&changes, "", a, b); err != nil {
+			return false, err
+		}
+		return len(changes) == 0, nil
+	}
+	return a.Eq(b)
+}
@@ -0,0 +1,162 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type change struct {
+		path     string
+		kind     ChangeKind
+		old, new string
+	}
+	tests := []struct {
+		name     string
+		old, new string
+		options  DiffOptions
+		expected []change
+	}{
+		{
+			name:     "equal",
+			old:      `{"a": [1, 2, {"b": null}], "c": "d"}`,
+			new:      `{"c":"d","a":[1,2,{"b":null}]}`,
+			expected: []change{},
+		},
+		{
+			name: "scalar",
+			old:  `1`,
+			new:  `2`,
+			expected: []change{
+				{path: "$", kind: Changed, old: "1", new: "2"},
+			},
+		},
+		{
+			name: "type",
+			old:  `{"a": 1}`,
+			new:  `{"a": "1"}`,
+			expected: []change{
+				{path: "$['a']", kind: TypeChanged, old: `1`, new: `"1"`},
+			},
+		},
+		{
+			name: "object",
+			old:  `{"a": 1, "b": 2, "c": {"d": true}}`,
+			new:  `{"a": 3, "c": {"d": false}, "e": 2}`,
+			expected: []change{
+				{path: "$['a']", kind: Changed, old: `1`, new: `3`},
+				{path: "$['b']", kind: Removed, old: `2`},
+				{path: "$['c']['d']", kind: Changed, old: `true`, new: `false`},
+				{path: "$['e']", kind: Added, new: `2`},
+			},
+		},
+		{
+			name: "array",
+			old:  `[1, 2, 3]`,
+			new:  `[1, 4]`,
+			expected: []change{
+				{path: "$[1]", kind: Changed, old: `2`, new: `4`},
+				{path: "$[2]", kind: Removed, old: `3`},
+			},
+		},
+		{
+			name: "array added",
+			old:  `{"a'b": []}`,
+			new:  `{"a'b": [null]}`,
+			expected: []change{
+				{path: `$['a\'b'][0]`, kind: Added, new: `null`},
+			},
+		},
+		{
+			name: "array order",
+			old:  `[1, 2, 3]`,
+			new:  `[3, 2, 1]`,
+			expected: []change{
+				{path: "$[0]", kind: Changed, old: `1`, new: `3`},
+				{path: "$[2]", kind: Changed, old: `3`, new: `1`},
+			},
+		},
+		{
+			name:     "ignore array order",
+			old:      `[1, 2, {"a": [3, 4]}]`,
+			new:      `[{"a": [4, 3]}, 2, 1]`,
+			options:  DiffOptions{IgnoreArrayOrder: true},
+			expected: []change{},
+		},
+		{
+			name:    "ignore array order: multiset",
+			old:     `[1, 1, 2]`,
+			new:     `[1, 2, 2]`,
+			options: DiffOptions{IgnoreArrayOrder: true},
+			expected: []change{
+				{path: "$[1]", kind: Removed, old: `1`},
+				{path: "$[2]", kind: Added, new: `2`},
+			},
+		},
+		{
+			name:     "float tolerance",
+			old:      `{"a": 1.0001, "b": [0.1]}`,
+			new:      `{"a": 1, "b": [0.1005]}`,
+			options:  DiffOptions{FloatTolerance: 0.001},
+			expected: []change{},
+		},
+		{
+			name:    "float tolerance exceeded",
+			old:     `[1.01]`,
+			new:     `[1]`,
+			options: DiffOptions{FloatTolerance: 0.001},
+			expected: []change{
+				{path: "$[0]", kind: Changed, old: `1.01`, new: `1`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := DiffWithOptions(Must(Unmarshal([]byte(test.old))), Must(Unmarshal([]byte(test.new))), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if len(result) != len(test.expected) {
+				t.Errorf("Wrong count of changes: %d, expected: %d, got: %v", len(result), len(test.expected), result)
+				return
+			}
+			for i, expected := range test.expected {
+				actual := result[i]
+				if actual.Path != expected.path || actual.Kind != expected.kind || actual.Old.String() != expected.old || actual.New.String() != expected.new {
+					t.Errorf("Wrong change #%d: {%s %s %s %s}, expected: %v", i, actual.Path, actual.Kind, actual.Old, actual.New, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDiff_nil(t *testing.T) {
+	if _, err := Diff(nil, NullNode("")); err == nil {
+		t.Errorf("Expected error")
+	}
+	if _, err := Diff(NullNode(""), nil); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestDiff_error(t *testing.T) {
+	broken := &Node{_type: Numeric, borders: [2]int{0, 1}, data: &[]byte{'x'}}
+	if _, err := Diff(broken, NumericNode("", 1)); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestChangeKind_String(t *testing.T) {
+	tests := map[ChangeKind]string{
+		Added:          "added",
+		Removed:        "removed",
+		Changed:        "changed",
+		TypeChanged:    "type-changed",
+		ChangeKind(-1): "unknown",
+	}
+	for kind, expected := range tests {
+		if kind.String() != expected {
+			t.Errorf("Wrong string: %s, expected: %s", kind, expected)
+		}
+	}
+}
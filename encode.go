@@ -1,90 +1,371 @@
 package ajson
 
 import (
+	"bytes"
+	"io"
+	"math"
 	"strconv"
 )
 
+// MarshalOptions are options for MarshalWithOptions
+type MarshalOptions struct {
+	// PreserveFormat rewrites only changed parts of the parsed containers: whitespaces, comments and order of keys
+	// of the source are kept. New values are added after the last one, with the same indentation.
+	// Source of every changed container is parsed again, so changes deep in large documents cost more.
+	PreserveFormat bool
+	// DisableHTMLEscape keeps '<', '>' and '&' in strings as is, instead of \u003c, \u003e and \u0026
+	DisableHTMLEscape bool
+	// DisableLineTerminatorEscape keeps U+2028 and U+2029 in strings as is, instead of \u2028 and \u2029
+	DisableLineTerminatorEscape bool
+	// ASCIIOnly escapes all non-ASCII characters as \uXXXX, with surrogate pairs for characters outside the BMP.
+	// Unchanged values with non-ASCII characters are marshaled again as well.
+	ASCIIOnly bool
+}
+
 // Marshal returns slice of bytes, marshaled from current value
 func Marshal(node *Node) (result []byte, err error) {
-	result = make([]byte, 0)
+	return MarshalWithOptions(node, MarshalOptions{})
+}
+
+// MarshalWithOptions returns slice of bytes, marshaled from current value with the given options.
+// Example:
+//
+//	root := Must(UnmarshalWithOptions([]byte(`{
+//		"name": "example", // comment
+//		"value": 1
+//	}`), Options{Relaxed: true}))
+//	_ = root.MustKey("value").SetNumeric(2)
+//	result, _ := MarshalWithOptions(root, MarshalOptions{PreserveFormat: true})
+//	// result == []byte(`{
+//	// 	"name": "example", // comment
+//	// 	"value": 2
+//	// }`)
+//
+func MarshalWithOptions(node *Node, options MarshalOptions) (result []byte, err error) {
+	return options.append(make([]byte, 0), node)
+}
+
+// AppendMarshal appends the marshaled value of the node to the dst and returns the extended slice.
+// Whole value is written in a single pass, without intermediate slices for children.
+func AppendMarshal(dst []byte, node *Node) ([]byte, error) {
+	return MarshalOptions{}.append(dst, node)
+}
+
+// MarshalTo writes the marshaled value of the node to the writer. Value is written by chunks, so the size of the
+// internal buffer doesn't depend on the size of the node.
+// Example:
+//
+//	root := Must(Unmarshal(data))
+//	_ = root.MustKey("value").SetNumeric(2)
+//	err := MarshalTo(os.Stdout, root)
+//
+func MarshalTo(writer io.Writer, node *Node) error {
+	return MarshalToWithOptions(writer, node, MarshalOptions{})
+}
+
+// MarshalToWithOptions writes the marshaled value of the node to the writer with the given options
+func MarshalToWithOptions(writer io.Writer, node *Node, options MarshalOptions) error {
+	m := &marshaler{
+		options: options,
+		buf:     make([]byte, 0, marshalChunk),
+		writer:  writer,
+	}
+	if err := m.node(node); err != nil {
+		return err
+	}
+	return m.write()
+}
+
+// marshalChunk is the size of data, which marshaler keeps before writing it to the writer
+const marshalChunk = 4096
+
+// marshaler appends marshaled values to the buffer, and flushes the buffer to the writer if it is set
+type marshaler struct {
+	options MarshalOptions
+	buf     []byte
+	writer  io.Writer
+}
+
+func (o MarshalOptions) append(dst []byte, node *Node) ([]byte, error) {
+	m := &marshaler{options: o, buf: dst}
+	if err := m.node(node); err != nil {
+		return nil, err
+	}
+	return m.buf, nil
+}
+
+// flush writes the buffer to the writer, if it's big enough
+func (m *marshaler) flush() error {
+	if m.writer == nil || len(m.buf) < marshalChunk {
+		return nil
+	}
+	return m.write()
+}
+
+func (m *marshaler) write() error {
+	if len(m.buf) == 0 {
+		return nil
+	}
+	_, err := m.writer.Write(m.buf)
+	m.buf = m.buf[:0]
+	return err
+}
+
+func (m *marshaler) node(node *Node) (err error) {
 	var (
 		sValue string
 		bValue bool
 		nValue float64
-		oValue []byte
 	)
 
 	if node == nil {
-		return nil, errorUnparsed()
-	} else if node.dirty {
+		return errorUnparsed()
+	} else if node.dirty && m.options.PreserveFormat && m.options.preservable(node) {
+		return m.preserve(node)
+	} else if node.dirty || m.options.rewrite(node) {
 		switch node._type {
 		case Null:
-			result = append(result, _null...)
+			m.buf = append(m.buf, _null...)
 		case Numeric:
 			nValue, err = node.GetNumeric()
 			if err != nil {
-				return nil, err
+				return err
+			}
+			// NaN and infinite values, e.g. of JSON5, YAML or CBOR, have no JSON representation
+			if math.IsNaN(nValue) || math.IsInf(nValue, 0) {
+				return errorRequest("unsupported value: %v", nValue)
 			}
-			result = append(result, strconv.FormatFloat(nValue, 'g', -1, 64)...)
+			m.buf = strconv.AppendFloat(m.buf, nValue, 'g', -1, 64)
 		case String:
 			sValue, err = node.GetString()
 			if err != nil {
-				return nil, err
+				return err
 			}
-			result = append(result, quotes)
-			result = append(result, quoteString(sValue, true)...)
-			result = append(result, quotes)
+			m.string(sValue)
 		case Bool:
 			bValue, err = node.GetBool()
 			if err != nil {
-				return nil, err
+				return err
 			} else if bValue {
-				result = append(result, _true...)
+				m.buf = append(m.buf, _true...)
 			} else {
-				result = append(result, _false...)
+				m.buf = append(m.buf, _false...)
 			}
 		case Array:
-			result = append(result, bracketL)
-			for i := 0; i < len(node.children); i++ {
+			m.buf = append(m.buf, bracketL)
+			for i, child := range node.children {
 				if i != 0 {
-					result = append(result, coma)
+					m.buf = append(m.buf, coma)
 				}
-				child, ok := node.children[strconv.Itoa(i)]
-				if !ok {
-					return nil, errorRequest("wrong length of array")
+				if err = m.node(child); err != nil {
+					return err
 				}
-				oValue, err = Marshal(child)
-				if err != nil {
-					return nil, err
-				}
-				result = append(result, oValue...)
 			}
-			result = append(result, bracketR)
+			m.buf = append(m.buf, bracketR)
 		case Object:
-			result = append(result, bracesL)
-			bValue = false
-			for key, child := range node.children {
-				if bValue {
-					result = append(result, coma)
-				} else {
-					bValue = true
+			m.buf = append(m.buf, bracesL)
+			for i, child := range node.children {
+				if i != 0 {
+					m.buf = append(m.buf, coma)
 				}
-				result = append(result, quotes)
-				result = append(result, quoteString(key, true)...)
-				result = append(result, quotes, colon)
-				oValue, err = Marshal(child)
-				if err != nil {
-					return nil, err
+				m.string(child.Key())
+				m.buf = append(m.buf, colon)
+				if err = m.node(child); err != nil {
+					return err
 				}
-				result = append(result, oValue...)
 			}
-			result = append(result, bracesR)
+			m.buf = append(m.buf, bracesR)
 		}
 	} else if node.ready() {
-		result = append(result, node.Source()...)
+		return m.source(node.Source())
 	} else {
-		return nil, errorUnparsed()
+		return errorUnparsed()
 	}
 
-	return
+	return m.flush()
+}
+
+// source appends the source of the unchanged node; big sources are written to the writer as is
+func (m *marshaler) source(source []byte) error {
+	if m.writer == nil || len(source) < marshalChunk {
+		m.buf = append(m.buf, source...)
+		return m.flush()
+	}
+	if err := m.write(); err != nil {
+		return err
+	}
+	_, err := m.writer.Write(source)
+	return err
+}
+
+// string appends the quoted string
+func (m *marshaler) string(value string) {
+	m.buf = append(m.buf, quotes)
+	m.buf = m.options.appendString(m.buf, value)
+	m.buf = append(m.buf, quotes)
+}
+
+// rewrite checks if the source of the unchanged node can't be used as is with the current options
+func (o MarshalOptions) rewrite(node *Node) bool {
+	return o.ASCIIOnly && node.ready() && !isASCII(node.Source())
+}
+
+// preservable checks if the node is a parsed container, which structure is not replaced
+func (o MarshalOptions) preservable(node *Node) bool {
+	if !node.isContainer() || node.origin != nil || node.data == nil || !node.ready() {
+		return false
+	}
+	if o.ASCIIOnly && !isASCII((*node.data)[node.borders[0]:node.borders[1]]) {
+		return false
+	}
+	if node.IsArray() {
+		return (*node.data)[node.borders[0]] == bracketL
+	}
+	return (*node.data)[node.borders[0]] == bracesL
+}
+
+// preserve appends the source of the container, where only changed, added and removed children are replaced
+func (m *marshaler) preserve(node *Node) (err error) {
+	source := (*node.data)[node.borders[0]:node.borders[1]]
+	entries, err := sourceEntries(source)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return m.entries(node, source)
+	}
+	if len(node.children) == 0 {
+		m.buf = append(m.buf, source[0], source[len(source)-1])
+		return nil
+	}
+	slots := make(map[string]int, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		slots[entries[i].key] = i
+	}
+	gap := append([]byte{coma}, whitespacesBefore(source, entries[len(entries)-1].start)...)
+	colon := defaultColon(source, entries[0])
+
+	m.buf = append(m.buf, source[:entries[0].start]...)
+	previous := -1
+	for i, child := range node.children {
+		slot := -1
+		if node.IsObject() {
+			if value, ok := slots[child.Key()]; ok {
+				slot = value
+			}
+		} else if data, borders := child.span(); data == node.data {
+			if value, ok := slots[spanKey(borders[0]-node.borders[0], borders[1]-node.borders[0])]; ok {
+				slot = value
+			}
+		}
+		if i != 0 {
+			if slot > 0 && previous == slot-1 {
+				m.buf = append(m.buf, source[entries[previous].borders[1]:entries[slot].start]...)
+			} else {
+				m.buf = append(m.buf, gap...)
+			}
+		}
+		previous = slot
+		if slot == -1 {
+			if node.IsObject() {
+				m.string(child.Key())
+				m.buf = append(m.buf, colon...)
+			}
+		} else {
+			entry := entries[slot]
+			m.buf = append(m.buf, source[entry.start:entry.borders[0]]...)
+			data, borders := child.span()
+			if !child.isContainer() && child.origin == nil && data == node.data &&
+				borders[0]-node.borders[0] == entry.borders[0] && borders[1]-node.borders[0] == entry.borders[1] {
+				m.buf = append(m.buf, source[entry.borders[0]:entry.borders[1]]...)
+				continue
+			}
+		}
+		if err = m.node(child); err != nil {
+			return err
+		}
+	}
+	m.buf = append(m.buf, source[entries[len(entries)-1].borders[1]:]...)
+	return nil
+}
+
+// entries appends the source of the empty container with all new children
+func (m *marshaler) entries(node *Node, source []byte) (err error) {
+	m.buf = append(m.buf, source[0])
+	for i, child := range node.children {
+		if i != 0 {
+			m.buf = append(m.buf, coma)
+		}
+		if node.IsObject() {
+			m.string(child.Key())
+			m.buf = append(m.buf, colon)
+		}
+		if err = m.node(child); err != nil {
+			return err
+		}
+	}
+	m.buf = append(m.buf, source[1:]...)
+	return nil
+}
+
+// entry is a child of the container in its source: start of the entry (key of an object's child) and borders of the value
+type entry struct {
+	key     string
+	start   int
+	borders [2]int
+}
+
+// sourceEntries returns all children of the container in order of appearance, borders are relative to the source.
+// Children of an Array are identified by their borders. Whole source is parsed again, so nested changed containers
+// cost O(depth*size): removed children are not kept by nodes, so their borders are known only from the source.
+func sourceEntries(source []byte) ([]entry, error) {
+	root, err := UnmarshalWithOptions(source, Options{Relaxed: true, DuplicateKeys: DuplicateKeysFirst})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry, 0, len(root.children))
+	buf := newBuffer(source)
+	buf.index = 1
+	for _, child := range root.children {
+		current := entry{key: child.Key(), start: child.borders[0], borders: child.borders}
+		if root.IsArray() {
+			current.key = spanKey(child.borders[0], child.borders[1])
+		} else {
+			_, _ = buf.skipRelaxed()
+			if source[buf.index] == coma {
+				buf.index++
+				_, _ = buf.skipRelaxed()
+			}
+			current.start = buf.index
+		}
+		entries = append(entries, current)
+		buf.index = child.borders[1]
+	}
+	return entries, nil
+}
+
+func spanKey(start, end int) string {
+	return strconv.Itoa(start) + ":" + strconv.Itoa(end)
+}
+
+// whitespacesBefore returns JSON whitespaces, just before the index
+func whitespacesBefore(source []byte, index int) []byte {
+	start := index
+	for start > 0 && (source[start-1] == skipS || source[start-1] == skipT || source[start-1] == skipN || source[start-1] == skipR) {
+		start--
+	}
+	return source[start:index]
+}
+
+// defaultColon returns the colon with whitespaces after it, from the entry of an object; or colon for an array
+func defaultColon(source []byte, first entry) []byte {
+	between := source[first.start:first.borders[0]]
+	index := bytes.LastIndexByte(between, colon)
+	if index == -1 {
+		return []byte{colon}
+	}
+	if spaces := whitespacesBefore(between, len(between)); len(spaces) == len(between)-index-1 {
+		return between[index:]
+	}
+	return []byte{colon}
 }
@@ -1,7 +1,12 @@
 package ajson
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -166,7 +171,7 @@ func TestMarshal_Errors(t *testing.T) {
 			name: "Array_1",
 			node: func() (node *Node) {
 				node = ArrayNode("", nil)
-				node.children["1"] = NullNode("1")
+				node.children = append(node.children, nil)
 				return
 			},
 		},
@@ -194,3 +199,375 @@ func TestMarshal_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshal_NaN(t *testing.T) {
+	tests := []struct {
+		name string
+		node func() (*Node, error)
+	}{
+		{name: "NaN", node: func() (*Node, error) { return NumericNode("", math.NaN()), nil }},
+		{name: "Inf", node: func() (*Node, error) { return ArrayNode("", []*Node{NumericNode("", math.Inf(-1))}), nil }},
+		{name: "JSON5", node: func() (*Node, error) {
+			return UnmarshalWithOptions([]byte(`[1, Infinity]`), Options{Relaxed: true})
+		}},
+		{name: "YAML", node: func() (*Node, error) { return UnmarshalYAML([]byte("a: .nan")) }},
+		{name: "CBOR", node: func() (*Node, error) { return UnmarshalCBOR([]byte{0x81, 0xf9, 0x7c, 0x00}) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node, err := test.node()
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			value, err := Marshal(node)
+			if err == nil {
+				t.Errorf("Expected error, got: %s", value)
+			} else if !strings.HasPrefix(err.Error(), "wrong request: unsupported value: ") {
+				t.Errorf("Wrong error: %s", err)
+			}
+		})
+	}
+}
+
+func TestMarshalWithOptions_PreserveFormat(t *testing.T) {
+	config := `{
+	// name of the service
+	"name": "example",
+	"server": {
+		"host": "localhost", /* default */
+		"port": 8080
+	},
+	"tags": [1, 2,  3],
+	"empty": {}
+}`
+	tests := []struct {
+		name     string
+		input    string
+		relaxed  bool
+		update   func(root *Node)
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			input:    config,
+			relaxed:  true,
+			update:   func(root *Node) {},
+			expected: config,
+		},
+		{
+			name:    "deep key",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("server").MustKey("port").SetNumeric(9090)
+			},
+			expected: strings.Replace(config, "8080", "9090", 1),
+		},
+		{
+			name:    "string",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("name").SetString("new \"name\"")
+			},
+			expected: strings.Replace(config, `"example"`, `"new \"name\""`, 1),
+		},
+		{
+			name:    "delete key",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("server").DeleteKey("port")
+			},
+			expected: strings.Replace(config, `"localhost", /* default */
+		"port": 8080`, `"localhost"`, 1),
+		},
+		{
+			name:    "delete first key",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.DeleteKey("name")
+			},
+			expected: strings.Replace(config, `"name": "example",
+	`, ``, 1),
+		},
+		{
+			name:    "append key",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("server").AppendObject("tls", BoolNode("", true))
+			},
+			expected: strings.Replace(config, `"port": 8080`, `"port": 8080,
+		"tls": true`, 1),
+		},
+		{
+			name:    "append to empty",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("empty").AppendObject("a", NullNode(""))
+			},
+			expected: strings.Replace(config, `{}`, `{"a":null}`, 1),
+		},
+		{
+			name:    "array",
+			input:   config,
+			relaxed: true,
+			update: func(root *Node) {
+				tags := root.MustKey("tags")
+				_ = tags.MustIndex(0).SetNumeric(10)
+				_ = tags.DeleteIndex(1)
+				_ = tags.AppendArray(NumericNode("", 4))
+			},
+			expected: strings.Replace(config, `[1, 2,  3]`, `[10,  3,  4]`, 1),
+		},
+		{
+			name:    "delete all",
+			input:   `{"a": [1, 2], "b": 1}`,
+			update: func(root *Node) {
+				_ = root.MustKey("a").DeleteIndex(0)
+				_ = root.MustKey("a").DeleteIndex(0)
+				_ = root.DeleteKey("b")
+			},
+			expected: `{"a": []}`,
+		},
+		{
+			name:  "set node",
+			input: `{"a": 1,  "b": 2}`,
+			update: func(root *Node) {
+				_ = root.MustKey("a").SetNode(Must(Unmarshal([]byte(`[ 1 ]`))))
+			},
+			expected: `{"a": [ 1 ],  "b": 2}`,
+		},
+		{
+			name:  "set object",
+			input: `{"a": { "b" : 1 },  "c": 2}`,
+			update: func(root *Node) {
+				_ = root.MustKey("a").SetObject(map[string]*Node{"d": NumericNode("", 3)})
+			},
+			expected: `{"a": {"d":3},  "c": 2}`,
+		},
+		{
+			name:    "relaxed values",
+			input:   `{a: 'x', b: 0x10, c: 1,}`,
+			relaxed: true,
+			update: func(root *Node) {
+				_ = root.MustKey("c").SetNumeric(2)
+			},
+			expected: `{a: 'x', b: 0x10, c: 2,}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(UnmarshalWithOptions([]byte(test.input), Options{Relaxed: test.relaxed}))
+			test.update(root)
+			result, err := MarshalWithOptions(root, MarshalOptions{PreserveFormat: true})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalWithOptions_default(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": 1,  "b": [1, 2]}`)))
+	_ = root.MustKey("a").SetNumeric(2)
+	result, err := MarshalWithOptions(root, MarshalOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if string(result) != `{"a":2,"b":[1, 2]}` {
+		t.Errorf("Wrong result: %s", result)
+	}
+}
+
+// dirtyTree returns a copy of the jsonExample, where all nodes are changed
+func dirtyTree() *Node {
+	root := Must(Unmarshal(jsonExample))
+	nodes, _ := root.JSONPath("$..*")
+	for _, node := range append(nodes, root) {
+		_, _ = node.Value()
+		node.dirty = true
+	}
+	return root
+}
+
+func TestAppendMarshal(t *testing.T) {
+	for name, root := range map[string]*Node{"source": Must(Unmarshal(jsonExample)), "dirty": dirtyTree()} {
+		t.Run(name, func(t *testing.T) {
+			expected, err := Marshal(root)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			result, err := AppendMarshal([]byte("prefix:"), root)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != "prefix:"+string(expected) {
+				t.Errorf("Wrong result: %s", result)
+			}
+		})
+	}
+	if result, err := AppendMarshal([]byte("prefix:"), nil); err == nil {
+		t.Errorf("Expected error, got: %s", result)
+	}
+}
+
+type countWriter struct {
+	bytes.Buffer
+	writes int
+	max    int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if len(p) > w.max {
+		w.max = len(p)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestMarshalTo(t *testing.T) {
+	values := make([]*Node, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, ObjectNode("", map[string]*Node{"value": NumericNode("", float64(i))}))
+	}
+	big := ArrayNode("", []*Node{
+		ArrayNode("", values),
+		Must(Unmarshal([]byte(`"` + strings.Repeat("a", 2*marshalChunk) + `"`))),
+	})
+	for name, root := range map[string]*Node{"source": Must(Unmarshal(jsonExample)), "dirty": dirtyTree(), "big": big} {
+		t.Run(name, func(t *testing.T) {
+			expected, err := Marshal(root)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			writer := new(countWriter)
+			if err = MarshalTo(writer, root); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if writer.String() != string(expected) {
+				t.Errorf("Wrong result: %s", writer.String())
+			}
+		})
+	}
+	writer := new(countWriter)
+	_ = MarshalTo(writer, big)
+	if writer.writes < 3 {
+		t.Errorf("Expected writes by chunks, got: %d", writer.writes)
+	}
+	if _, err := Unmarshal(writer.Bytes()); err != nil {
+		t.Errorf("Result is not a valid JSON: %s", err)
+	}
+}
+
+func TestMarshalTo_errors(t *testing.T) {
+	if err := MarshalTo(new(bytes.Buffer), ArrayNode("", []*Node{valueNode(nil, "", Bool, 1)})); err == nil {
+		t.Errorf("Expected error")
+	}
+	expected := errors.New("write error")
+	if err := MarshalTo(&failWriter{err: expected}, NumericNode("", 1)); err != expected {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	root := dirtyTree()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Marshal(root)
+	}
+}
+
+func BenchmarkMarshalTo(b *testing.B) {
+	root := dirtyTree()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = MarshalTo(ioutil.Discard, root)
+	}
+}
+
+func TestMarshalWithOptions_escaping(t *testing.T) {
+	value := "<a&b>\u2028\u2029 \u043a \U0001F600 \"\n"
+	tests := []struct {
+		name     string
+		options  MarshalOptions
+		expected string
+	}{
+		{
+			name:     "default",
+			options:  MarshalOptions{},
+			expected: "\"\\u003ca\\u0026b\\u003e\\u2028\\u2029 \u043a \U0001F600 \\\"\\n\"",
+		},
+		{
+			name:     "DisableHTMLEscape",
+			options:  MarshalOptions{DisableHTMLEscape: true},
+			expected: "\"<a&b>\\u2028\\u2029 \u043a \U0001F600 \\\"\\n\"",
+		},
+		{
+			name:     "DisableLineTerminatorEscape",
+			options:  MarshalOptions{DisableLineTerminatorEscape: true},
+			expected: "\"\\u003ca\\u0026b\\u003e\u2028\u2029 \u043a \U0001F600 \\\"\\n\"",
+		},
+		{
+			name:     "ASCIIOnly",
+			options:  MarshalOptions{ASCIIOnly: true},
+			expected: "\"\\u003ca\\u0026b\\u003e\\u2028\\u2029 \\u043a \\ud83d\\ude00 \\\"\\n\"",
+		},
+		{
+			name:     "ASCIIOnly without other escapes",
+			options:  MarshalOptions{ASCIIOnly: true, DisableHTMLEscape: true, DisableLineTerminatorEscape: true},
+			expected: "\"<a&b>\\u2028\\u2029 \\u043a \\ud83d\\ude00 \\\"\\n\"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := MarshalWithOptions(StringNode("", value), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+			if test.options.ASCIIOnly && !isASCII(result) {
+				t.Errorf("Result is not ASCII: %s", result)
+			}
+			if decoded := Must(Unmarshal(result)).MustString(); decoded != value {
+				t.Errorf("Wrong decoded value: %q", decoded)
+			}
+		})
+	}
+}
+
+func TestMarshalWithOptions_ASCIIOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		preserve bool
+		expected string
+	}{
+		{name: "ascii source", input: `{"a": [1, "b"]}`, expected: `{"a": [1, "b"]}`},
+		{name: "non-ascii key", input: `{"ключ": [1, "b"]}`, expected: `{"\u043a\u043b\u044e\u0447":[1, "b"]}`},
+		{name: "non-ascii value", input: `[1, {"a": "é"}, [2]]`, expected: `[1,{"a":"\u00e9"},[2]]`},
+		{name: "preserve", input: "{\n\t\"a\": 1,\n\t\"b\": \"é\"\n}", preserve: true, expected: `{"a":2,"b":"\u00e9"}`},
+		{name: "preserve ascii", input: "{\n\t\"a\": 1,\n\t\"b\": \"e\"\n}", preserve: true, expected: "{\n\t\"a\": 2,\n\t\"b\": \"e\"\n}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if test.preserve {
+				_ = root.MustKey("a").SetNumeric(2)
+			}
+			result, err := MarshalWithOptions(root, MarshalOptions{ASCIIOnly: true, PreserveFormat: test.preserve})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package ajson
+
+import (
+	"io"
+	"math"
+	"strconv"
+)
+
+// Encoder writes JSON to the io.Writer value by value, without building a tree of nodes.
+// Structure of the JSON is validated on each call: keys are allowed only in objects, containers should be closed
+// in the right order and only one root value can be written.
+// Example:
+//
+//	encoder := NewEncoder(os.Stdout)
+//	_ = encoder.BeginObject()
+//	_ = encoder.Key("name")
+//	_ = encoder.String("example")
+//	_ = encoder.Key("values")
+//	_ = encoder.BeginArray()
+//	_ = encoder.Number(1)
+//	_ = encoder.Node(Must(Unmarshal([]byte(`{"a": true}`))))
+//	_ = encoder.EndArray()
+//	_ = encoder.EndObject()
+//	err := encoder.Close()
+//	// Output:
+//	// {"name":"example","values":[1,{"a": true}]}
+//
+type Encoder struct {
+	writer  io.Writer
+	stack   []encoderLevel
+	done    bool
+	err     error
+	scratch []byte
+	options MarshalOptions
+}
+
+// encoderLevel is an opened container of the Encoder
+type encoderLevel struct {
+	_type NodeType
+	size  int
+	key   bool
+}
+
+// NewEncoder creates an Encoder, which writes to the writer
+func NewEncoder(writer io.Writer) *Encoder {
+	return NewEncoderWithOptions(writer, MarshalOptions{})
+}
+
+// NewEncoderWithOptions creates an Encoder, which writes to the writer with the given escaping options
+func NewEncoderWithOptions(writer io.Writer, options MarshalOptions) *Encoder {
+	return &Encoder{
+		writer:  writer,
+		stack:   make([]encoderLevel, 0),
+		options: options,
+	}
+}
+
+// BeginObject writes an opening curly bracket of the object
+func (e *Encoder) BeginObject() error {
+	return e.begin(Object, bracesL)
+}
+
+// EndObject writes a closing curly bracket of the current object
+func (e *Encoder) EndObject() error {
+	return e.end(Object, bracesR)
+}
+
+// BeginArray writes an opening square bracket of the array
+func (e *Encoder) BeginArray() error {
+	return e.begin(Array, bracketL)
+}
+
+// EndArray writes a closing square bracket of the current array
+func (e *Encoder) EndArray() error {
+	return e.end(Array, bracketR)
+}
+
+// Key writes a key of the next value of the current object
+func (e *Encoder) Key(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+	top := e.top()
+	if top == nil || top._type != Object || top.key {
+		return errorRequest("unexpected key '%s'", key)
+	}
+	e.scratch = e.scratch[:0]
+	if top.size != 0 {
+		e.scratch = append(e.scratch, coma)
+	}
+	e.scratch = append(e.scratch, quotes)
+	e.scratch = e.options.appendString(e.scratch, key)
+	e.scratch = append(e.scratch, quotes, colon)
+	top.key = true
+	return e.write(e.scratch)
+}
+
+// String writes a string value
+func (e *Encoder) String(value string) error {
+	if err := e.value(); err != nil {
+		return err
+	}
+	e.scratch = append(e.scratch, quotes)
+	e.scratch = e.options.appendString(e.scratch, value)
+	e.scratch = append(e.scratch, quotes)
+	return e.write(e.scratch)
+}
+
+// Number writes a numeric value, NaN and infinite values are not allowed
+func (e *Encoder) Number(value float64) error {
+	if e.err != nil {
+		return e.err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return errorRequest("unsupported value: %v", value)
+	}
+	if err := e.value(); err != nil {
+		return err
+	}
+	e.scratch = strconv.AppendFloat(e.scratch, value, 'g', -1, 64)
+	return e.write(e.scratch)
+}
+
+// Bool writes a boolean value
+func (e *Encoder) Bool(value bool) error {
+	if err := e.value(); err != nil {
+		return err
+	}
+	if value {
+		e.scratch = append(e.scratch, _true...)
+	} else {
+		e.scratch = append(e.scratch, _false...)
+	}
+	return e.write(e.scratch)
+}
+
+// Null writes a null value
+func (e *Encoder) Null() error {
+	if err := e.value(); err != nil {
+		return err
+	}
+	e.scratch = append(e.scratch, _null...)
+	return e.write(e.scratch)
+}
+
+// Node writes the whole node as a value, in the same way as MarshalToWithOptions does.
+// If the node can't be marshaled, the stream is broken and the same error will be returned by all next calls.
+func (e *Encoder) Node(node *Node) error {
+	if err := e.value(); err != nil {
+		return err
+	}
+	if err := e.write(e.scratch); err != nil {
+		return err
+	}
+	if err := MarshalToWithOptions(e.writer, node, e.options); err != nil {
+		e.err = err
+	}
+	return e.err
+}
+
+// Close checks that the root value was written and all containers are closed. Writer will not be closed.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if !e.done || len(e.stack) != 0 {
+		return errorRequest("JSON is not completed")
+	}
+	return nil
+}
+
+func (e *Encoder) begin(_type NodeType, symbol byte) error {
+	if err := e.value(); err != nil {
+		return err
+	}
+	e.stack = append(e.stack, encoderLevel{_type: _type})
+	e.done = false
+	e.scratch = append(e.scratch, symbol)
+	return e.write(e.scratch)
+}
+
+func (e *Encoder) end(_type NodeType, symbol byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	top := e.top()
+	if top == nil || top._type != _type || top.key {
+		return errorRequest("unexpected '%c'", symbol)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	e.done = len(e.stack) == 0
+	e.scratch = append(e.scratch[:0], symbol)
+	return e.write(e.scratch)
+}
+
+// value validates the position of the next value and prepares the scratch buffer with its separator
+func (e *Encoder) value() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.scratch = e.scratch[:0]
+	top := e.top()
+	if top == nil {
+		if e.done {
+			return errorRequest("root value was already written")
+		}
+		e.done = true
+		return nil
+	}
+	switch top._type {
+	case Object:
+		if !top.key {
+			return errorRequest("key expected")
+		}
+		top.key = false
+	default:
+		if top.size != 0 {
+			e.scratch = append(e.scratch, coma)
+		}
+	}
+	top.size++
+	return nil
+}
+
+func (e *Encoder) top() *encoderLevel {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return &e.stack[len(e.stack)-1]
+}
+
+func (e *Encoder) write(data []byte) error {
+	if _, err := e.writer.Write(data); err != nil {
+		e.err = err
+	}
+	return e.err
+}
@@ -0,0 +1,243 @@
+package ajson
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+type failWriter struct {
+	after int
+	err   error
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.after == 0 {
+		return 0, w.err
+	}
+	w.after--
+	return len(p), nil
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    func(e *Encoder) error
+		expected string
+	}{
+		{
+			name:     "number",
+			steps:    func(e *Encoder) error { return e.Number(1.5) },
+			expected: `1.5`,
+		},
+		{
+			name:     "string",
+			steps:    func(e *Encoder) error { return e.String("a\"b\n<>") },
+			expected: `"a\"b\n\u003c\u003e"`,
+		},
+		{
+			name: "empty containers",
+			steps: func(e *Encoder) error {
+				_ = e.BeginArray()
+				_ = e.BeginObject()
+				_ = e.EndObject()
+				_ = e.BeginArray()
+				_ = e.EndArray()
+				return e.EndArray()
+			},
+			expected: `[{},[]]`,
+		},
+		{
+			name: "object",
+			steps: func(e *Encoder) error {
+				_ = e.BeginObject()
+				_ = e.Key("a")
+				_ = e.Bool(true)
+				_ = e.Key("b")
+				_ = e.Null()
+				_ = e.Key("c\"")
+				_ = e.BeginArray()
+				_ = e.Number(1)
+				_ = e.Number(-2e-10)
+				_ = e.Bool(false)
+				_ = e.EndArray()
+				return e.EndObject()
+			},
+			expected: `{"a":true,"b":null,"c\"":[1,-2e-10,false]}`,
+		},
+		{
+			name: "node",
+			steps: func(e *Encoder) error {
+				_ = e.BeginArray()
+				_ = e.Number(1)
+				_ = e.Node(Must(Unmarshal([]byte(`{"a": [1, 2]}`))))
+				_ = e.Node(NumericNode("", 2))
+				return e.EndArray()
+			},
+			expected: `[1,{"a": [1, 2]},2]`,
+		},
+		{
+			name: "node as key value",
+			steps: func(e *Encoder) error {
+				_ = e.BeginObject()
+				_ = e.Key("a")
+				_ = e.Node(Must(Unmarshal([]byte(`[true]`))))
+				_ = e.Key("b")
+				_ = e.String("c")
+				return e.EndObject()
+			},
+			expected: `{"a":[true],"b":"c"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			encoder := NewEncoder(buf)
+			if err := test.steps(encoder); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if err := encoder.Close(); err != nil {
+				t.Errorf("Unexpected error on close: %s", err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", buf.String(), test.expected)
+			}
+			if _, err := Unmarshal(buf.Bytes()); err != nil {
+				t.Errorf("Result is not a valid JSON: %s", err)
+			}
+		})
+	}
+}
+
+func TestEncoder_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(e *Encoder) error
+	}{
+		{name: "key on root", steps: func(e *Encoder) error { return e.Key("a") }},
+		{name: "key in array", steps: func(e *Encoder) error {
+			_ = e.BeginArray()
+			return e.Key("a")
+		}},
+		{name: "value without key", steps: func(e *Encoder) error {
+			_ = e.BeginObject()
+			return e.Number(1)
+		}},
+		{name: "key after key", steps: func(e *Encoder) error {
+			_ = e.BeginObject()
+			_ = e.Key("a")
+			return e.Key("b")
+		}},
+		{name: "close object after key", steps: func(e *Encoder) error {
+			_ = e.BeginObject()
+			_ = e.Key("a")
+			return e.EndObject()
+		}},
+		{name: "wrong closing bracket", steps: func(e *Encoder) error {
+			_ = e.BeginArray()
+			return e.EndObject()
+		}},
+		{name: "closing on root", steps: func(e *Encoder) error { return e.EndArray() }},
+		{name: "second root value", steps: func(e *Encoder) error {
+			_ = e.Null()
+			return e.Null()
+		}},
+		{name: "second root container", steps: func(e *Encoder) error {
+			_ = e.BeginArray()
+			_ = e.EndArray()
+			return e.BeginObject()
+		}},
+		{name: "NaN", steps: func(e *Encoder) error { return e.Number(math.NaN()) }},
+		{name: "Inf", steps: func(e *Encoder) error { return e.Number(math.Inf(-1)) }},
+		{name: "dirty node", steps: func(e *Encoder) error { return e.Node(&Node{_type: Numeric, dirty: true}) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.steps(NewEncoder(new(bytes.Buffer))); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestEncoder_Close(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(e *Encoder)
+	}{
+		{name: "empty", steps: func(e *Encoder) {}},
+		{name: "opened array", steps: func(e *Encoder) {
+			_ = e.BeginArray()
+			_ = e.Number(1)
+		}},
+		{name: "opened object", steps: func(e *Encoder) {
+			_ = e.BeginObject()
+			_ = e.Key("a")
+			_ = e.BeginObject()
+			_ = e.EndObject()
+		}},
+		{name: "key without value", steps: func(e *Encoder) {
+			_ = e.BeginObject()
+			_ = e.Key("a")
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoder := NewEncoder(new(bytes.Buffer))
+			test.steps(encoder)
+			if err := encoder.Close(); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestEncoder_writeError(t *testing.T) {
+	expected := errors.New("write error")
+	encoder := NewEncoder(&failWriter{after: 1, err: expected})
+	if err := encoder.BeginArray(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if err := encoder.Number(1); err != expected {
+		t.Errorf("Wrong error: %v", err)
+	}
+	if err := encoder.EndArray(); err != expected {
+		t.Errorf("Expected the same error, got: %v", err)
+	}
+	if err := encoder.Close(); err != expected {
+		t.Errorf("Expected the same error on close, got: %v", err)
+	}
+}
+
+func TestEncoder_Node_error(t *testing.T) {
+	encoder := NewEncoder(new(bytes.Buffer))
+	_ = encoder.BeginArray()
+	err := encoder.Node(nil)
+	if err == nil {
+		t.Errorf("Expected error")
+		return
+	}
+	if next := encoder.EndArray(); next != err {
+		t.Errorf("Expected the same error, got: %v", next)
+	}
+}
+
+func TestNewEncoderWithOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	encoder := NewEncoderWithOptions(buf, MarshalOptions{ASCIIOnly: true, DisableHTMLEscape: true})
+	_ = encoder.BeginObject()
+	_ = encoder.Key("<ключ>")
+	_ = encoder.BeginArray()
+	_ = encoder.String("é")
+	_ = encoder.Node(Must(Unmarshal([]byte(`["ё"]`))))
+	_ = encoder.EndArray()
+	_ = encoder.EndObject()
+	if err := encoder.Close(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if expected := `{"<\u043a\u043b\u044e\u0447>":["\u00e9",["\u0451"]]}`; buf.String() != expected {
+		t.Errorf("Wrong result: %s, expected: %s", buf.String(), expected)
+	}
+}
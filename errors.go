@@ -27,6 +27,19 @@ const (
 	Unparsed
 	// UnsupportedType means that wrong type was given
 	UnsupportedType
+	// DuplicateKey means that object has a repeated key
+	DuplicateKey
+	// MaxDepthExceeded means that nesting of arrays and objects is deeper than Options.MaxDepth, or than 10000
+	// levels of MessagePack and CBOR values
+	MaxDepthExceeded
+	// MaxBytesExceeded means that data is larger than Options.MaxBytes
+	MaxBytesExceeded
+	// MaxStringLenExceeded means that string or key is longer than Options.MaxStringLen
+	MaxStringLenExceeded
+	// MaxKeysExceeded means that object has more keys than Options.MaxKeys
+	MaxKeysExceeded
+	// MaxArrayLenExceeded means that array has more elements than Options.MaxArrayLen
+	MaxArrayLenExceeded
 )
 
 func errorSymbol(b *buffer) error {
@@ -56,6 +69,22 @@ func errorEOF(b *buffer) error {
 	}
 }
 
+func errorDuplicate(index int, key string) error {
+	return Error{
+		Type:    DuplicateKey,
+		Index:   index,
+		Message: key,
+	}
+}
+
+func errorLimit(_type ErrorType, index int, limit int) error {
+	return Error{
+		Type:  _type,
+		Index: index,
+		Value: limit,
+	}
+}
+
 func errorType() error {
 	return Error{
 		Type: WrongType,
@@ -97,6 +126,18 @@ func (err Error) Error() string {
 		return "not parsed yet"
 	case WrongRequest:
 		return fmt.Sprintf("wrong request: %s", err.Message)
+	case DuplicateKey:
+		return fmt.Sprintf("duplicate key '%s' at %d", err.Message, err.Index)
+	case MaxDepthExceeded:
+		return fmt.Sprintf("max depth %v exceeded at %d", err.Value, err.Index)
+	case MaxBytesExceeded:
+		return fmt.Sprintf("max size %v bytes exceeded at %d", err.Value, err.Index)
+	case MaxStringLenExceeded:
+		return fmt.Sprintf("max string length %v exceeded at %d", err.Value, err.Index)
+	case MaxKeysExceeded:
+		return fmt.Sprintf("max keys count %v exceeded at %d", err.Value, err.Index)
+	case MaxArrayLenExceeded:
+		return fmt.Sprintf("max array length %v exceeded at %d", err.Value, err.Index)
 	}
 	return fmt.Sprintf("unknown error: '%s' at %d", []byte{err.Char}, err.Index)
 }
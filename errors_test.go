@@ -12,6 +12,7 @@ func TestError_Error(t *testing.T) {
 		{name: "UnexpectedEOF", _type: UnexpectedEOF, message: "unexpected end of file"},
 		{name: "WrongType", _type: WrongType, message: "wrong type of Node"},
 		{name: "WrongRequest", _type: WrongRequest, message: "wrong request: example error"},
+		{name: "DuplicateKey", _type: DuplicateKey, message: "duplicate key 'example error' at 10"},
 		{name: "unknown", _type: -666, message: "unknown error: 'S' at 10"},
 	}
 	for _, test := range tests {
@@ -29,6 +30,26 @@ func TestError_Error(t *testing.T) {
 	}
 }
 
+func Test_errorLimit(t *testing.T) {
+	tests := []struct {
+		_type   ErrorType
+		message string
+	}{
+		{_type: MaxDepthExceeded, message: "max depth 5 exceeded at 10"},
+		{_type: MaxBytesExceeded, message: "max size 5 bytes exceeded at 10"},
+		{_type: MaxStringLenExceeded, message: "max string length 5 exceeded at 10"},
+		{_type: MaxKeysExceeded, message: "max keys count 5 exceeded at 10"},
+		{_type: MaxArrayLenExceeded, message: "max array length 5 exceeded at 10"},
+	}
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			if result := errorLimit(test._type, 10, 5).Error(); result != test.message {
+				t.Errorf("Wrong error message: %s", result)
+			}
+		})
+	}
+}
+
 func Test_unsupportedType(t *testing.T) {
 	f := 1.
 	type args struct {
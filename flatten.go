@@ -0,0 +1,175 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// flatEntry is the node of the tree in process of Unflatten: either the value or members by keys in order of appearance
+type flatEntry struct {
+	value   *Node
+	keys    []string
+	members map[string]*flatEntry
+}
+
+// Flatten converts the object or the array into the object with single-level keys: keys and indexes of nested values
+// are joined with the separator, "." if the separator is empty. The separator and '\' in keys are escaped with '\'.
+// Empty objects and arrays are kept as values.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"a": {"b": [1, 2], "c.d": {}}}`)))
+//	result, _ := Flatten(root, ".")
+//	// result.String() == `{"a.b.0":1,"a.b.1":2,"a.c\\.d":{}}`
+//
+func Flatten(node *Node, separator string) (*Node, error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	}
+	if !node.isContainer() {
+		return nil, errorType()
+	}
+	if separator == "" {
+		separator = "."
+	}
+	result := ObjectNode("", nil)
+	if err := flatten(result, node, "", separator); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Unflatten is the reverse of Flatten: keys of the object are split by the unescaped separator into nested objects.
+// Objects, which keys are exactly indexes from 0 to N-1 in any order, become arrays.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"a.b.1": 2, "a.b.0": 1, "a.c\\.d": {}}`)))
+//	result, _ := Unflatten(root, ".")
+//	// result.String() == `{"a":{"b":[1,2],"c.d":{}}}`
+//
+func Unflatten(node *Node, separator string) (*Node, error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	}
+	if !node.IsObject() {
+		return nil, errorType()
+	}
+	if separator == "" {
+		separator = "."
+	}
+	root := &flatEntry{members: make(map[string]*flatEntry)}
+	for _, child := range node.children {
+		if child == nil {
+			return nil, errorUnparsed()
+		}
+		if err := root.set(child.Key(), flattenSplit(child.Key(), separator), child); err != nil {
+			return nil, err
+		}
+	}
+	return root.node(), nil
+}
+
+func flatten(result *Node, node *Node, prefix string, separator string) error {
+	for i, child := range node.children {
+		if child == nil {
+			return errorUnparsed()
+		}
+		var name string
+		if node.IsArray() {
+			name = prefix + strconv.Itoa(i)
+		} else {
+			name = prefix + flattenEscape(child.Key(), separator)
+		}
+		if child.isContainer() && len(child.children) != 0 {
+			if err := flatten(result, child, name+separator, separator); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := result.AppendObject(name, child.Clone()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenEscape escapes '\' and the separator in the key
+func flattenEscape(key string, separator string) string {
+	key = strings.Replace(key, `\`, `\\`, -1)
+	return strings.Replace(key, separator, `\`+separator, -1)
+}
+
+// flattenSplit splits the key by the separator, which is not escaped, and unescapes parts
+func flattenSplit(key string, separator string) []string {
+	result := make([]string, 0)
+	part := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		switch {
+		case strings.HasPrefix(key[i:], separator):
+			result = append(result, string(part))
+			part = part[:0]
+			i += len(separator) - 1
+		case key[i] == '\\' && strings.HasPrefix(key[i+1:], separator):
+			part = append(part, separator...)
+			i += len(separator)
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '\\':
+			part = append(part, '\\')
+			i++
+		default:
+			part = append(part, key[i])
+		}
+	}
+	return append(result, string(part))
+}
+
+// set sets the value by the path of keys
+func (e *flatEntry) set(key string, path []string, value *Node) error {
+	for i, name := range path {
+		child, ok := e.members[name]
+		if !ok {
+			child = &flatEntry{}
+			if i != len(path)-1 {
+				child.members = make(map[string]*flatEntry)
+			}
+			e.keys = append(e.keys, name)
+			e.members[name] = child
+		} else if i == len(path)-1 || child.members == nil {
+			return errorRequest("key %q conflicts with other keys", key)
+		}
+		e = child
+	}
+	e.value = value
+	return nil
+}
+
+// node returns the node of the entry: the clone of the value, the array or the object
+func (e *flatEntry) node() *Node {
+	if e.value != nil {
+		return e.value.Clone()
+	}
+	if e.isArray() {
+		result := ArrayNode("", nil)
+		for i := range e.keys {
+			_ = result.AppendArray(e.members[strconv.Itoa(i)].node())
+		}
+		return result
+	}
+	result := ObjectNode("", nil)
+	for _, key := range e.keys {
+		_ = result.AppendObject(key, e.members[key].node())
+	}
+	return result
+}
+
+// isArray checks if keys of the entry are exactly indexes from 0 to N-1
+func (e *flatEntry) isArray() bool {
+	if len(e.keys) == 0 {
+		return false
+	}
+	for _, key := range e.keys {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(e.keys) || strconv.Itoa(index) != key {
+			return false
+		}
+	}
+	return true
+}
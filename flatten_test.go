@@ -0,0 +1,109 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		separator string
+		expected  string
+	}{
+		{name: "empty", input: `{}`, expected: `{}`},
+		{name: "nested", input: `{"a": {"b": [1, 2]}, "c": "x"}`, expected: `{"a.b.0":1,"a.b.1":2,"c":"x"}`},
+		{name: "array", input: `[{"a": null}, [true]]`, expected: `{"0.a":null,"1.0":true}`},
+		{name: "empty containers", input: `{"a": {}, "b": [], "c": [{}]}`, expected: `{"a":{},"b":[],"c.0":{}}`},
+		{name: "escape", input: `{"a.b": {"c\\d": 1, "": 2}}`, expected: `{"a\\.b.c\\\\d":1,"a\\.b.":2}`},
+		{name: "separator", input: `{"a": {"b__c": [1]}}`, separator: "__", expected: `{"a__b\\__c__0":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Flatten(Must(Unmarshal([]byte(test.input))), test.separator)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if value := result.String(); value != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestFlatten_errors(t *testing.T) {
+	if _, err := Flatten(nil, "."); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+	if _, err := Flatten(NumericNode("", 1), "."); err == nil {
+		t.Errorf("Expected error for numeric")
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		separator string
+		expected  string
+	}{
+		{name: "empty", input: `{}`, expected: `{}`},
+		{name: "nested", input: `{"a.b.1": 2, "a.b.0": 1, "c": "x"}`, expected: `{"a":{"b":[1,2]},"c":"x"}`},
+		{name: "root array", input: `{"0.a": null, "1.0": true}`, expected: `[{"a":null},[true]]`},
+		{name: "sparse", input: `{"a.0": 1, "a.2": 3}`, expected: `{"a":{"0":1,"2":3}}`},
+		{name: "leading zero", input: `{"a.00": 1}`, expected: `{"a":{"00":1}}`},
+		{name: "values", input: `{"a": {"b": [1]}, "c": []}`, expected: `{"a":{"b": [1]},"c":[]}`},
+		{name: "escape", input: `{"a\\.b.c\\\\d": 1, "a\\.b.": 2, "e\\f": 3}`, expected: `{"a.b":{"c\\d":1,"":2},"e\\f":3}`},
+		{name: "separator", input: `{"a__b\\__c__0": 1}`, separator: "__", expected: `{"a":{"b__c":[1]}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Unflatten(Must(Unmarshal([]byte(test.input))), test.separator)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if value := result.String(); value != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnflatten_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "prefix", input: `{"a": 1, "a.b": 2}`, err: `wrong request: key "a.b" conflicts with other keys`},
+		{name: "nested", input: `{"a.b": 1, "a": 2}`, err: `wrong request: key "a" conflicts with other keys`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Unflatten(Must(Unmarshal([]byte(test.input))), ".")
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+	if _, err := Unflatten(Must(Unmarshal([]byte(`[]`))), "."); err == nil {
+		t.Errorf("Expected error for array")
+	}
+}
+
+func TestFlatten_roundTrip(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": {"b.c": [1, {"d\\": [true, null]}], "e": {}}, "f": "g"}`)))
+	for _, separator := range []string{".", "/", "::"} {
+		flat, err := Flatten(root, separator)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		result, err := Unflatten(flat, separator)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if ok, err := result.Eq(root); err != nil || !ok {
+			t.Errorf("Wrong result for %q: %s, expected: %s", separator, result, root)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Package gogen generates Go type definitions with json tags from sample JSON documents.
+//
+// Shapes of all samples and all array elements are merged: numbers are int64 if all numeric literals are integers
+// and float64 otherwise, keys which are sometimes null become pointers, keys which are sometimes missing get
+// the omitempty option, and values of different types become interface{}.
+//
+// Example:
+//
+//	source, _ := Generate(Options{Name: "User"}, ajson.Must(ajson.Unmarshal([]byte(`{"id": 1, "tags": ["a"], "score": null}`))))
+//	// type User struct {
+//	// 	ID    int64       `json:"id"`
+//	// 	Tags  []string    `json:"tags"`
+//	// 	Score interface{} `json:"score"`
+//	// }
+//
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spyzhov/ajson"
+)
+
+// Options are options of the generation
+type Options struct {
+	// Name of the root type, "Root" by default
+	Name string
+	// Package is the name of the package for the package clause, it's omitted if empty
+	Package string
+}
+
+// shape is the merged description of all sample values at the same place of documents
+type shape struct {
+	null    bool
+	boolean bool
+	integer bool
+	float   bool
+	string  bool
+	array   bool
+	object  bool
+	elem    *shape
+	keys    []string
+	members map[string]*shape
+	counts  map[string]int
+	objects int
+}
+
+// generator writes type definitions and keeps names of all generated types
+type generator struct {
+	buf   *bytes.Buffer
+	names map[string]bool
+	queue []named
+}
+
+// named is the object shape, which should be generated as a named struct type
+type named struct {
+	name  string
+	shape *shape
+}
+
+// initialisms are the parts of names which should be written in upper case
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true,
+	"RHS": true, "RPC": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// Generate returns the formatted Go source with type definitions, which describe all samples
+func Generate(options Options, samples ...*ajson.Node) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("gogen: no samples")
+	}
+	root := new(shape)
+	for _, sample := range samples {
+		if sample == nil {
+			return nil, fmt.Errorf("gogen: sample is nil")
+		}
+		if err := root.add(sample); err != nil {
+			return nil, err
+		}
+	}
+	name := options.Name
+	if name == "" {
+		name = "Root"
+	}
+	g := &generator{
+		buf:   new(bytes.Buffer),
+		names: make(map[string]bool),
+	}
+	if options.Package != "" {
+		_, _ = fmt.Fprintf(g.buf, "package %s\n\n", options.Package)
+	}
+	name = g.unique(exported(name))
+	if root.kind() == "object" {
+		g.queue = append(g.queue, named{name: name, shape: root})
+	} else {
+		_, _ = fmt.Fprintf(g.buf, "type %s %s\n\n", name, g.typeOf(root, name, false))
+	}
+	for len(g.queue) != 0 {
+		current := g.queue[0]
+		g.queue = g.queue[1:]
+		g.object(current.name, current.shape)
+	}
+	return format.Source(append(bytes.TrimRight(g.buf.Bytes(), "\n"), '\n'))
+}
+
+func (s *shape) add(node *ajson.Node) error {
+	switch node.Type() {
+	case ajson.Null:
+		s.null = true
+	case ajson.Bool:
+		s.boolean = true
+	case ajson.Numeric:
+		integer, err := isInteger(node)
+		if err != nil {
+			return err
+		}
+		if integer {
+			s.integer = true
+		} else {
+			s.float = true
+		}
+	case ajson.String:
+		s.string = true
+	case ajson.Array:
+		s.array = true
+		if s.elem == nil {
+			s.elem = new(shape)
+		}
+		for _, child := range node.MustArray() {
+			if err := s.elem.add(child); err != nil {
+				return err
+			}
+		}
+	case ajson.Object:
+		s.object = true
+		s.objects++
+		if s.members == nil {
+			s.members = make(map[string]*shape)
+			s.counts = make(map[string]int)
+		}
+		for _, key := range node.Keys() {
+			member, ok := s.members[key]
+			if !ok {
+				member = new(shape)
+				s.members[key] = member
+				s.keys = append(s.keys, key)
+			}
+			s.counts[key]++
+			if err := member.add(node.MustKey(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// kind returns the Go kind of not null values: bool, int, float, string, array, object; empty if there are no values
+// and mixed if values have different types
+func (s *shape) kind() string {
+	kinds := make([]string, 0, 1)
+	if s.boolean {
+		kinds = append(kinds, "bool")
+	}
+	if s.integer || s.float {
+		kinds = append(kinds, "number")
+	}
+	if s.string {
+		kinds = append(kinds, "string")
+	}
+	if s.array {
+		kinds = append(kinds, "array")
+	}
+	if s.object {
+		kinds = append(kinds, "object")
+	}
+	switch len(kinds) {
+	case 0:
+		return ""
+	case 1:
+		return kinds[0]
+	}
+	return "mixed"
+}
+
+// typeOf returns the Go type of the shape, name is used for the nested struct types
+func (g *generator) typeOf(s *shape, name string, pointer bool) string {
+	var result string
+	switch s.kind() {
+	case "bool":
+		result = "bool"
+	case "number":
+		result = "int64"
+		if s.float {
+			result = "float64"
+		}
+	case "string":
+		result = "string"
+	case "array":
+		if s.elem.kind() == "" {
+			return "[]interface{}"
+		}
+		return "[]" + g.typeOf(s.elem, singular(name), s.elem.null)
+	case "object":
+		result = g.unique(name)
+		g.queue = append(g.queue, named{name: result, shape: s})
+	default:
+		return "interface{}"
+	}
+	if pointer && s.null {
+		return "*" + result
+	}
+	return result
+}
+
+func (g *generator) object(name string, s *shape) {
+	_, _ = fmt.Fprintf(g.buf, "type %s struct {\n", name)
+	fields := make(map[string]bool, len(s.keys))
+	for _, key := range s.keys {
+		field := exported(key)
+		for i := 2; fields[field]; i++ {
+			field = exported(key) + strconv.Itoa(i)
+		}
+		fields[field] = true
+		tag := key
+		if s.counts[key] < s.objects {
+			tag += ",omitempty"
+		}
+		_, _ = fmt.Fprintf(g.buf, "\t%s %s `json:%s`\n", field, g.typeOf(s.members[key], field, true), strconv.Quote(tag))
+	}
+	_, _ = fmt.Fprint(g.buf, "}\n\n")
+}
+
+// unique returns the name, which was not used for other types
+func (g *generator) unique(name string) string {
+	result := name
+	for i := 2; g.names[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	g.names[result] = true
+	return result
+}
+
+// isInteger checks the numeric literal of the node, or its value if the node was changed
+func isInteger(node *ajson.Node) (bool, error) {
+	if source := node.Source(); source != nil {
+		return !bytes.ContainsAny(source, ".eE"), nil
+	}
+	value, err := node.GetNumeric()
+	if err != nil {
+		return false, err
+	}
+	return value == math.Trunc(value) && !math.IsInf(value, 0), nil
+}
+
+// exported returns the exported Go identifier from the key, e.g. "user_id" -> "UserID"
+func exported(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		for _, word := range words(part) {
+			upper := strings.ToUpper(word)
+			if initialisms[upper] {
+				result = append(result, upper)
+			} else {
+				runes := []rune(word)
+				runes[0] = unicode.ToUpper(runes[0])
+				result = append(result, string(runes))
+			}
+		}
+	}
+	name := strings.Join(result, "")
+	if name == "" {
+		return "Field"
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		return "X" + name
+	}
+	return name
+}
+
+// words splits the camelCase part into words, e.g. "userId" -> "user", "Id"
+func words(part string) []string {
+	result := make([]string, 0, 1)
+	runes := []rune(part)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			result = append(result, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(result, string(runes[start:]))
+}
+
+// singular returns the name of the array element, e.g. "Items" -> "Item", "Categories" -> "Category"
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name + "Item"
+}
@@ -0,0 +1,176 @@
+package gogen
+
+import (
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		samples  []string
+		expected string
+	}{
+		{
+			name:    "object",
+			options: Options{},
+			samples: []string{`{"id": 1, "name": "a", "price": 1.0, "active": true, "ratio": 1e3}`},
+			expected: "type Root struct {\n" +
+				"\tID     int64   `json:\"id\"`\n" +
+				"\tName   string  `json:\"name\"`\n" +
+				"\tPrice  float64 `json:\"price\"`\n" +
+				"\tActive bool    `json:\"active\"`\n" +
+				"\tRatio  float64 `json:\"ratio\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "package",
+			options: Options{Name: "user", Package: "api"},
+			samples: []string{`{"user_id": 1}`},
+			expected: "package api\n\n" +
+				"type User struct {\n" +
+				"\tUserID int64 `json:\"user_id\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "merged samples",
+			options: Options{},
+			samples: []string{`{"a": 1, "b": null, "c": "x"}`, `{"a": 2.5, "b": "y", "d": [1]}`},
+			expected: "type Root struct {\n" +
+				"\tA float64 `json:\"a\"`\n" +
+				"\tB *string `json:\"b\"`\n" +
+				"\tC string  `json:\"c,omitempty\"`\n" +
+				"\tD []int64 `json:\"d,omitempty\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "nested",
+			options: Options{},
+			samples: []string{`{"address": {"city": "x"}, "categories": [{"id": 1}, {"id": 2, "parent": {"id": 1}}, null]}`},
+			expected: "type Root struct {\n" +
+				"\tAddress    Address     `json:\"address\"`\n" +
+				"\tCategories []*Category `json:\"categories\"`\n" +
+				"}\n\n" +
+				"type Address struct {\n" +
+				"\tCity string `json:\"city\"`\n" +
+				"}\n\n" +
+				"type Category struct {\n" +
+				"\tID     int64  `json:\"id\"`\n" +
+				"\tParent Parent `json:\"parent,omitempty\"`\n" +
+				"}\n\n" +
+				"type Parent struct {\n" +
+				"\tID int64 `json:\"id\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "sometimes null object",
+			options: Options{},
+			samples: []string{`{"owner": null}`, `{"owner": {"name": "a"}}`},
+			expected: "type Root struct {\n" +
+				"\tOwner *Owner `json:\"owner\"`\n" +
+				"}\n\n" +
+				"type Owner struct {\n" +
+				"\tName string `json:\"name\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "mixed and empty",
+			options: Options{},
+			samples: []string{`{"a": [1, "b"], "b": [], "c": null, "d": [[1], [2.5]]}`},
+			expected: "type Root struct {\n" +
+				"\tA []interface{} `json:\"a\"`\n" +
+				"\tB []interface{} `json:\"b\"`\n" +
+				"\tC interface{}   `json:\"c\"`\n" +
+				"\tD [][]float64   `json:\"d\"`\n" +
+				"}\n",
+		},
+		{
+			name:    "names",
+			options: Options{},
+			samples: []string{`{"userName": 1, "user_name": 2, "2fa": true, "": 3, "html-url": "x", "Root": {"a": 1}}`},
+			expected: "type Root struct {\n" +
+				"\tUserName  int64  `json:\"userName\"`\n" +
+				"\tUserName2 int64  `json:\"user_name\"`\n" +
+				"\tX2fa      bool   `json:\"2fa\"`\n" +
+				"\tField     int64  `json:\"\"`\n" +
+				"\tHTMLURL   string `json:\"html-url\"`\n" +
+				"\tRoot      Root2  `json:\"Root\"`\n" +
+				"}\n\n" +
+				"type Root2 struct {\n" +
+				"\tA int64 `json:\"a\"`\n" +
+				"}\n",
+		},
+		{
+			name:     "array root",
+			options:  Options{Name: "Users"},
+			samples:  []string{`[{"id": 1}]`},
+			expected: "type Users []User\n\ntype User struct {\n\tID int64 `json:\"id\"`\n}\n",
+		},
+		{
+			name:     "scalar root",
+			options:  Options{},
+			samples:  []string{`"a"`},
+			expected: "type Root string\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples := make([]*ajson.Node, 0, len(test.samples))
+			for _, sample := range test.samples {
+				samples = append(samples, ajson.Must(ajson.Unmarshal([]byte(sample))))
+			}
+			result, err := Generate(test.options, samples...)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestGenerate_changed(t *testing.T) {
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"a": 1.5}`)))
+	_ = root.MustKey("a").SetNumeric(2)
+	_ = root.AppendObject("b", ajson.NumericNode("", 2.5))
+	result, err := Generate(Options{}, root)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if expected := "type Root struct {\n\tA int64   `json:\"a\"`\n\tB float64 `json:\"b\"`\n}\n"; string(result) != expected {
+		t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, expected)
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	if _, err := Generate(Options{}); err == nil {
+		t.Errorf("Expected error without samples")
+	}
+	if _, err := Generate(Options{}, nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+	if _, err := Generate(Options{Package: "1"}, ajson.NullNode("")); err == nil {
+		t.Errorf("Expected error for wrong package")
+	}
+}
+
+func Test_exported(t *testing.T) {
+	tests := map[string]string{
+		"id":          "ID",
+		"userId":      "UserID",
+		"user_id":     "UserID",
+		"api-key":     "APIKey",
+		"HTTPServer":  "HTTPServer",
+		"ключ":        "Ключ",
+		"$ref":        "Ref",
+		"123":         "X123",
+		"__":          "Field",
+		"already_URL": "AlreadyURL",
+	}
+	for key, expected := range tests {
+		if value := exported(key); value != expected {
+			t.Errorf("Wrong name for %s: %s, expected: %s", key, value, expected)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package ajson
+
+import (
+	"math"
+)
+
+// inferEnumLimit is the maximum count of distinct string values, which are listed as an enum
+const inferEnumLimit = 10
+
+// inference is the summary of all sample values at the same place of documents
+type inference struct {
+	count   int
+	types   map[string]bool
+	strings []string
+	seen    map[string]bool
+	total   int
+	minimum float64
+	maximum float64
+	numbers int
+	items   *inference
+	keys    []string
+	members map[string]*inference
+	objects int
+}
+
+// inferTypes is the order of types in the inferred schema
+var inferTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// InferSchema returns the JSON Schema (draft 2020-12), which describes all samples: types of values are merged,
+// keys that are present in all objects are required, strings with a few repeated values are listed as an enum and
+// numbers get the range of sample values.
+// Example:
+//
+//	schema := InferSchema(
+//		Must(Unmarshal([]byte(`{"id": 1, "status": "active"}`))),
+//		Must(Unmarshal([]byte(`{"id": 2, "status": "active", "note": null}`))),
+//	)
+//	// schema.String() == `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object",
+//	// 	"properties":{"id":{"type":"integer","minimum":1,"maximum":2},"status":{"type":"string","enum":["active"]},
+//	// 	"note":{"type":"null"}},"required":["id","status"]}`
+//
+func InferSchema(samples ...*Node) *Node {
+	summary := new(inference)
+	for _, sample := range samples {
+		if sample != nil {
+			summary.add(sample)
+		}
+	}
+	root := ObjectNode("", nil)
+	_ = root.AppendObject("$schema", StringNode("", "https://json-schema.org/draft/2020-12/schema"))
+	summary.fill(root)
+	return root
+}
+
+func (i *inference) add(node *Node) {
+	if i.types == nil {
+		i.types = make(map[string]bool)
+	}
+	i.count++
+	switch node.Type() {
+	case Null:
+		i.types["null"] = true
+	case Bool:
+		i.types["boolean"] = true
+	case Numeric:
+		value, err := node.GetNumeric()
+		if err != nil {
+			return
+		}
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			i.types["integer"] = true
+		} else {
+			i.types["number"] = true
+		}
+		if i.numbers == 0 || value < i.minimum {
+			i.minimum = value
+		}
+		if i.numbers == 0 || value > i.maximum {
+			i.maximum = value
+		}
+		i.numbers++
+	case String:
+		value, err := node.GetString()
+		if err != nil {
+			return
+		}
+		i.types["string"] = true
+		i.total++
+		if i.seen == nil {
+			i.seen = make(map[string]bool)
+		}
+		if !i.seen[value] && len(i.seen) <= inferEnumLimit {
+			i.seen[value] = true
+			i.strings = append(i.strings, value)
+		}
+	case Array:
+		i.types["array"] = true
+		if i.items == nil {
+			i.items = new(inference)
+		}
+		for _, child := range node.children {
+			i.items.add(child)
+		}
+	case Object:
+		i.types["object"] = true
+		i.objects++
+		if i.members == nil {
+			i.members = make(map[string]*inference)
+		}
+		for _, child := range node.children {
+			key := child.Key()
+			member, ok := i.members[key]
+			if !ok {
+				member = new(inference)
+				i.members[key] = member
+				i.keys = append(i.keys, key)
+			}
+			member.add(child)
+		}
+	}
+}
+
+// schema returns the schema of all values of the inference
+func (i *inference) schema() *Node {
+	result := ObjectNode("", nil)
+	i.fill(result)
+	return result
+}
+
+// fill appends keywords of the schema to the result
+func (i *inference) fill(result *Node) {
+	types := make([]*Node, 0, len(i.types))
+	for _, name := range inferTypes {
+		if i.types[name] && !(name == "integer" && i.types["number"]) {
+			types = append(types, StringNode("", name))
+		}
+	}
+	if len(types) == 1 {
+		_ = result.AppendObject("type", types[0])
+	} else if len(types) > 1 {
+		_ = result.AppendObject("type", ArrayNode("", types))
+	}
+	if i.types["string"] && len(i.seen) <= inferEnumLimit && len(i.seen) < i.total && len(i.types) == 1 {
+		values := make([]*Node, 0, len(i.strings))
+		for _, value := range i.strings {
+			values = append(values, StringNode("", value))
+		}
+		_ = result.AppendObject("enum", ArrayNode("", values))
+	}
+	if i.numbers != 0 {
+		_ = result.AppendObject("minimum", NumericNode("", i.minimum))
+		_ = result.AppendObject("maximum", NumericNode("", i.maximum))
+	}
+	if i.items != nil && i.items.count != 0 {
+		_ = result.AppendObject("items", i.items.schema())
+	}
+	if i.objects != 0 {
+		properties := ObjectNode("", nil)
+		required := make([]*Node, 0, len(i.keys))
+		for _, key := range i.keys {
+			member := i.members[key]
+			_ = properties.AppendObject(key, member.schema())
+			if member.count == i.objects {
+				required = append(required, StringNode("", key))
+			}
+		}
+		_ = result.AppendObject("properties", properties)
+		if len(required) != 0 {
+			_ = result.AppendObject("required", ArrayNode("", required))
+		}
+	}
+}
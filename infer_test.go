@@ -0,0 +1,91 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		expected string
+	}{
+		{
+			name:     "empty",
+			samples:  []string{},
+			expected: `{}`,
+		},
+		{
+			name:     "scalar",
+			samples:  []string{`1`, `3`, `2`},
+			expected: `{"type":"integer","minimum":1,"maximum":3}`,
+		},
+		{
+			name:     "integer and number",
+			samples:  []string{`1`, `-2.5`},
+			expected: `{"type":"number","minimum":-2.5,"maximum":1}`,
+		},
+		{
+			name:     "types",
+			samples:  []string{`"a"`, `null`, `true`, `1`, `[]`, `{}`},
+			expected: `{"type":["null","boolean","integer","string","array","object"],"minimum":1,"maximum":1,"properties":{}}`,
+		},
+		{
+			name:     "enum",
+			samples:  []string{`"a"`, `"b"`, `"a"`},
+			expected: `{"type":"string","enum":["a","b"]}`,
+		},
+		{
+			name:     "unique strings",
+			samples:  []string{`"a"`, `"b"`, `"c"`},
+			expected: `{"type":"string"}`,
+		},
+		{
+			name:     "high cardinality",
+			samples:  []string{`["a","b","c","d","e","f","g","h","i","j","k","a","b","c","d","e","f","g","h","i","j","k"]`},
+			expected: `{"type":"array","items":{"type":"string"}}`,
+		},
+		{
+			name:     "nullable enum",
+			samples:  []string{`"a"`, `"a"`, `null`},
+			expected: `{"type":["null","string"]}`,
+		},
+		{
+			name:     "required",
+			samples:  []string{`{"a": 1, "b": "x"}`, `{"b": "y", "a": 2.5, "c": null}`},
+			expected: `{"type":"object","properties":{"a":{"type":"number","minimum":1,"maximum":2.5},"b":{"type":"string"},"c":{"type":"null"}},"required":["a","b"]}`,
+		},
+		{
+			name:     "nested",
+			samples:  []string{`{"items": [{"id": 1}, {"id": 2, "tags": ["x"]}]}`, `{"items": []}`},
+			expected: `{"type":"object","properties":{"items":{"type":"array","items":{"type":"object","properties":{"id":{"type":"integer","minimum":1,"maximum":2},"tags":{"type":"array","items":{"type":"string"}}},"required":["id"]}}},"required":["items"]}`,
+		},
+		{
+			name:     "empty arrays",
+			samples:  []string{`[]`, `[]`},
+			expected: `{"type":"array"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples := make([]*Node, 0, len(test.samples))
+			for _, sample := range test.samples {
+				samples = append(samples, Must(Unmarshal([]byte(sample))))
+			}
+			result := InferSchema(samples...)
+			if value := result.MustKey("$schema").MustString(); value != "https://json-schema.org/draft/2020-12/schema" {
+				t.Errorf("Wrong $schema: %s", value)
+			}
+			_ = result.DeleteKey("$schema")
+			if value := result.String(); value != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestInferSchema_nil(t *testing.T) {
+	if value := InferSchema(nil, NumericNode("", 1)).String(); value != `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer","minimum":1,"maximum":1}` {
+		t.Errorf("Wrong result: %s", value)
+	}
+}
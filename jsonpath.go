@@ -411,9 +411,8 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 						}
 
 						for i := ikeys[0]; i < ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
+							if i < len(element.children) {
+								temporary = append(temporary, element.children[i])
 							}
 						}
 					} else if ikeys[2] < 0 {
@@ -425,9 +424,8 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 						}
 
 						for i := ikeys[0]; i > ikeys[1]; i += ikeys[2] {
-							value, ok := element.children[strconv.Itoa(i)]
-							if ok {
-								temporary = append(temporary, value)
+							if i >= 0 && i < len(element.children) {
+								temporary = append(temporary, element.children[i])
 							}
 						}
 					}
@@ -480,7 +478,7 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 						if err != nil {
 							return nil, errorRequest("wrong type convert: %s", err.Error())
 						}
-						value = element.children[key]
+						value, _ = element.getChild(key)
 					case Numeric:
 						num, err = temp.getInteger()
 						if err == nil { // INTEGER
@@ -496,7 +494,7 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 							}
 							key = strconv.FormatFloat(float, 'g', -1, 64)
 						}
-						value = element.children[key]
+						value, _ = element.getChild(key)
 					case Bool:
 						ok, err = temp.GetBool()
 						if err != nil {
@@ -547,7 +545,7 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 							} else {
 								num = getPositiveIndex(int(fkeys[0]), element.Size())
 								key = strconv.Itoa(num)
-								value, ok = element.children[key]
+								value, ok = element.getChild(key)
 							}
 						} else {
 							key, _ = str(key)
@@ -558,13 +556,13 @@ func ApplyJSONPath(node *Node, commands []string) (result []*Node, err error) {
 							} else {
 								num = getPositiveIndex(num, element.Size())
 								key = strconv.Itoa(num)
-								value, ok = element.children[key]
+								value, ok = element.getChild(key)
 							}
 						}
 
 					} else if element.IsObject() {
 						key, _ = str(key)
-						value, ok = element.children[key]
+						value, ok = element.getChild(key)
 					}
 					if ok {
 						temporary = append(temporary, value)
@@ -1459,7 +1459,7 @@ func TestApplyJSONPath(t *testing.T) {
 				node:     array,
 				commands: []string{"$", "1"},
 			},
-			wantResult: []*Node{array.children["1"]},
+			wantResult: []*Node{array.children[1]},
 			wantErr:    false,
 		},
 		{
@@ -1468,7 +1468,7 @@ func TestApplyJSONPath(t *testing.T) {
 				node:     array,
 				commands: []string{"$", "1,0"},
 			},
-			wantResult: []*Node{array.children["1"], array.children["0"]},
+			wantResult: []*Node{array.children[1], array.children[0]},
 			wantErr:    false,
 		},
 	}
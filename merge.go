@@ -0,0 +1,216 @@
+package ajson
+
+import (
+	"strconv"
+)
+
+// MergeMode is a mode of merging values
+type MergeMode int
+
+const (
+	// MergeDeep merges objects recursively, other values are replaced
+	MergeDeep MergeMode = iota
+	// MergeReplace replaces the value, objects as well
+	MergeReplace
+	// MergeAppend appends elements of the source array to the destination array
+	MergeAppend
+	// MergeUnion merges elements of arrays of objects with the same value of the key, other elements are appended;
+	// without the key only elements, which are not in the destination array, are appended
+	MergeUnion
+)
+
+// MergeNull is a behaviour of null values of sources
+type MergeNull int
+
+const (
+	// NullReplace sets null as any other value
+	NullReplace MergeNull = iota
+	// NullIgnore skips null values: the destination keeps its value
+	NullIgnore
+	// NullDelete removes the member from the destination object, as JSON Merge Patch (RFC 7396) does
+	NullDelete
+)
+
+// MergeStrategy is a strategy of merging values
+type MergeStrategy struct {
+	// Mode of merging, MergeDeep by default
+	Mode MergeMode
+	// Key is the key of objects to match elements of arrays in MergeUnion mode, e.g. "name"
+	Key string
+}
+
+// MergeOptions are options for MergeWithOptions
+type MergeOptions struct {
+	// Strategy is the default strategy of values
+	Strategy MergeStrategy
+	// Paths are strategies of values by their normalized JSONPath relative to the destination, as Node.Path returns:
+	// "$['items']", quotes and backslashes of keys are escaped with '\'. Index "[*]" matches any element of the array:
+	// "$['servers'][*]['ports']".
+	Paths map[string]MergeStrategy
+	// Null is the behaviour of null values of sources, NullReplace by default
+	Null MergeNull
+}
+
+// Merge merges sources into the destination in order: objects are merged recursively, other values are replaced.
+// Example:
+//
+//	dst := Must(Unmarshal([]byte(`{"a": {"b": 1, "c": [1]}}`)))
+//	_ = Merge(dst, Must(Unmarshal([]byte(`{"a": {"c": [2], "d": true}}`))))
+//	// dst.String() == `{"a":{"b":1,"c":[2],"d":true}}`
+//
+func Merge(dst *Node, srcs ...*Node) error {
+	return MergeWithOptions(dst, MergeOptions{}, srcs...)
+}
+
+// MergeWithOptions merges sources into the destination in order with strategies of options.
+// Example:
+//
+//	dst := Must(Unmarshal([]byte(`{"items": [{"name": "a", "value": 1}], "tags": ["x"]}`)))
+//	_ = MergeWithOptions(dst, MergeOptions{
+//		Paths: map[string]MergeStrategy{
+//			"$['items']": {Mode: MergeUnion, Key: "name"},
+//			"$['tags']":  {Mode: MergeAppend},
+//		},
+//	}, Must(Unmarshal([]byte(`{"items": [{"name": "a", "value": 2}, {"name": "b"}], "tags": ["y"]}`))))
+//	// dst.String() == `{"items":[{"name":"a","value":2},{"name": "b"}],"tags":["x","y"]}`
+//
+func MergeWithOptions(dst *Node, options MergeOptions, srcs ...*Node) error {
+	if dst == nil {
+		return errorUnparsed()
+	}
+	for _, src := range srcs {
+		if src == nil {
+			return errorUnparsed()
+		}
+		if err := options.merge("$", "$", dst, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge merges the source into the destination, path is the exact path of the destination, generic is the path
+// with "[*]" instead of indexes
+func (o MergeOptions) merge(path, generic string, dst, src *Node) error {
+	if src.IsNull() && o.Null != NullReplace {
+		// the member is removed by the parent object, other values are kept
+		return nil
+	}
+	strategy := o.strategy(path, generic)
+	switch {
+	case strategy.Mode == MergeReplace:
+	case dst.IsObject() && src.IsObject():
+		return o.mergeObject(path, generic, dst, src)
+	case dst.IsArray() && src.IsArray() && strategy.Mode == MergeAppend:
+		for _, child := range src.children {
+			if err := dst.AppendArray(child.Clone()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case dst.IsArray() && src.IsArray() && strategy.Mode == MergeUnion:
+		return o.mergeUnion(path, generic, strategy.Key, dst, src)
+	}
+	return dst.SetNode(src)
+}
+
+func (o MergeOptions) mergeObject(path, generic string, dst, src *Node) error {
+	for _, child := range src.children {
+		if child == nil {
+			return errorUnparsed()
+		}
+		key := child.Key()
+		name := "['" + escapeJSONPathKey(key) + "']"
+		current, ok := dst.members[key]
+		switch {
+		case child.IsNull() && o.Null == NullIgnore:
+		case child.IsNull() && o.Null == NullDelete:
+			if ok {
+				if err := dst.DeleteKey(key); err != nil {
+					return err
+				}
+			}
+		case ok:
+			if err := o.merge(path+name, generic+name, current, child); err != nil {
+				return err
+			}
+		case child.IsObject():
+			// new objects are merged into the empty one to apply strategies and the null behaviour to their members
+			value := ObjectNode("", nil)
+			if err := dst.AppendObject(key, value); err != nil {
+				return err
+			}
+			if err := o.mergeObject(path+name, generic+name, value, child); err != nil {
+				return err
+			}
+		default:
+			if err := dst.AppendObject(key, child.Clone()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeUnion merges elements of the source array into the destination one: objects with the same value of the key
+// are merged, other elements are appended, if the destination has no equal element
+func (o MergeOptions) mergeUnion(path, generic, key string, dst, src *Node) error {
+	for _, child := range src.children {
+		if child == nil {
+			return errorUnparsed()
+		}
+		index, err := mergeMatch(dst, child, key)
+		if err != nil {
+			return err
+		}
+		if index < 0 {
+			if err = dst.AppendArray(child.Clone()); err != nil {
+				return err
+			}
+		} else if key != "" {
+			if err = o.merge(path+"["+strconv.Itoa(index)+"]", generic+"[*]", dst.children[index], child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeMatch returns the index of the element of the array, which matches the value: the object with the same value
+// of the key, or the equal element without the key; -1 if there is no such element
+func mergeMatch(array, value *Node, key string) (int, error) {
+	var id *Node
+	if key != "" {
+		if !value.IsObject() || !value.HasKey(key) {
+			return -1, nil
+		}
+		id = value.members[key]
+	}
+	for i, element := range array.children {
+		var ok bool
+		var err error
+		if key == "" {
+			ok, err = element.Eq(value)
+		} else if element.IsObject() && element.HasKey(key) {
+			ok, err = element.members[key].Eq(id)
+		}
+		if err != nil {
+			return -1, err
+		}
+		if ok {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// strategy returns the strategy of the exact path, the generic path or the default one
+func (o MergeOptions) strategy(path, generic string) MergeStrategy {
+	if strategy, ok := o.Paths[path]; ok {
+		return strategy
+	}
+	if strategy, ok := o.Paths[generic]; ok {
+		return strategy
+	}
+	return o.Strategy
+}
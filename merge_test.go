@@ -0,0 +1,135 @@
+package ajson
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dst := Must(Unmarshal([]byte(`{"a": {"b": 1, "c": [1]}, "d": "x"}`)))
+	err := Merge(dst,
+		Must(Unmarshal([]byte(`{"a": {"c": [2], "e": true}}`))),
+		Must(Unmarshal([]byte(`{"d": {"f": null}, "a": {"b": 2}}`))),
+	)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if ok, _ := dst.Eq(Must(Unmarshal([]byte(`{"a":{"b":2,"c":[2],"e":true},"d":{"f":null}}`)))); !ok {
+		t.Errorf("Wrong result: %s", dst)
+	}
+	if !dst.IsDirty() {
+		t.Errorf("Expected dirty node")
+	}
+}
+
+func TestMergeWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      string
+		src      string
+		options  MergeOptions
+		expected string
+	}{
+		{name: "scalar", dst: `1`, src: `"a"`, expected: `"a"`},
+		{name: "types", dst: `{"a": [1]}`, src: `{"a": {"b": 1}}`, expected: `{"a":{"b":1}}`},
+		{
+			name:     "replace",
+			dst:      `{"a": {"b": 1}, "c": {"d": 1}}`,
+			src:      `{"a": {"e": 2}, "c": {"f": 2}}`,
+			options:  MergeOptions{Paths: map[string]MergeStrategy{"$['a']": {Mode: MergeReplace}}},
+			expected: `{"a":{"e":2},"c":{"d":1,"f":2}}`,
+		},
+		{
+			name:     "append",
+			dst:      `{"a": [1, 2], "b": [1]}`,
+			src:      `{"a": [2, 3], "b": [2]}`,
+			options:  MergeOptions{Strategy: MergeStrategy{Mode: MergeAppend}},
+			expected: `{"a":[1,2,2,3],"b":[1,2]}`,
+		},
+		{
+			name:     "union",
+			dst:      `{"a": [1, 2, {"b": 1}]}`,
+			src:      `{"a": [2, 3, {"b": 1}, {"b": 2}]}`,
+			options:  MergeOptions{Strategy: MergeStrategy{Mode: MergeUnion}},
+			expected: `{"a":[1,2,{"b":1},3,{"b":2}]}`,
+		},
+		{
+			name:     "union by key",
+			dst:      `{"items": [{"name": "a", "value": 1, "tags": ["x"]}, {"name": "b"}, 1]}`,
+			src:      `{"items": [{"name": "a", "value": 2, "tags": ["y"]}, {"name": "c"}, {"value": 3}, 1]}`,
+			options:  MergeOptions{Paths: map[string]MergeStrategy{"$['items']": {Mode: MergeUnion, Key: "name"}}},
+			expected: `{"items":[{"name":"a","value":2,"tags":["y"]},{"name":"b"},1,{"name":"c"},{"value":3},1]}`,
+		},
+		{
+			name: "wildcard",
+			dst:  `{"servers": [{"name": "a", "ports": [80]}]}`,
+			src:  `{"servers": [{"name": "a", "ports": [443]}]}`,
+			options: MergeOptions{Paths: map[string]MergeStrategy{
+				"$['servers']":             {Mode: MergeUnion, Key: "name"},
+				"$['servers'][*]['ports']": {Mode: MergeAppend},
+			}},
+			expected: `{"servers":[{"name":"a","ports":[80,443]}]}`,
+		},
+		{name: "null replace", dst: `{"a": 1, "b": 2}`, src: `{"a": null, "c": null}`, expected: `{"a":null,"b":2,"c":null}`},
+		{
+			name:     "null ignore",
+			dst:      `{"a": 1, "b": {"c": 2}}`,
+			src:      `{"a": null, "b": {"c": null, "d": null}, "e": {"f": null}}`,
+			options:  MergeOptions{Null: NullIgnore},
+			expected: `{"a":1,"b":{"c":2},"e":{}}`,
+		},
+		{
+			name:     "null delete",
+			dst:      `{"a": 1, "b": {"c": 2, "d": 3}, "g": [1]}`,
+			src:      `{"a": null, "b": {"c": null}, "e": {"f": null}, "g": [null]}`,
+			options:  MergeOptions{Null: NullDelete},
+			expected: `{"b":{"d":3},"g":[null],"e":{}}`,
+		},
+		{
+			name:     "escaped key",
+			dst:      `{"a'b": {"c": 1}}`,
+			src:      `{"a'b": {"d": 2}}`,
+			options:  MergeOptions{Paths: map[string]MergeStrategy{`$['a\'b']`: {Mode: MergeReplace}}},
+			expected: `{"a'b":{"d":2}}`,
+		},
+		{name: "null root", dst: `{"a": 1}`, src: `null`, options: MergeOptions{Null: NullDelete}, expected: `{"a": 1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dst := Must(Unmarshal([]byte(test.dst)))
+			err := MergeWithOptions(dst, test.options, Must(Unmarshal([]byte(test.src))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if ok, _ := dst.Eq(Must(Unmarshal([]byte(test.expected)))); !ok {
+				t.Errorf("Wrong result: %s, expected: %s", dst, test.expected)
+			}
+		})
+	}
+}
+
+func TestMerge_layers(t *testing.T) {
+	defaults := Must(Unmarshal([]byte(`{"db": {"host": "localhost", "port": 5432}, "debug": false}`)))
+	env := Must(Unmarshal([]byte(`{"db": {"host": "db.local"}}`)))
+	local := Must(Unmarshal([]byte(`{"debug": true}`)))
+	dst := defaults.Clone()
+	if err := Merge(dst, env, local); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	result, err := Marshal(dst)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if string(result) != `{"db":{"host":"db.local","port":5432},"debug":true}` {
+		t.Errorf("Wrong result: %s", result)
+	}
+	if env.MustKey("db").Parent() != env || defaults.MustKey("debug").MustBool() {
+		t.Errorf("Sources were changed")
+	}
+}
+
+func TestMerge_errors(t *testing.T) {
+	if err := Merge(nil, NullNode("")); err == nil {
+		t.Errorf("Expected error for nil destination")
+	}
+	if err := Merge(NullNode(""), nil); err == nil {
+		t.Errorf("Expected error for nil source")
+	}
+}
@@ -0,0 +1,323 @@
+package ajson
+
+import (
+	"encoding/base64"
+	"math"
+)
+
+// MarshalMsgPack returns the MessagePack encoding of the node. Integers are written in the smallest integer format,
+// other numbers as float32 if it keeps the value, or as float64.
+// Objects {"$binary": "base64"} are written as binary values, objects {"$ext": type, "$binary": "base64"} as extensions.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"a": [1, -1.5], "b": {"$binary": "AQI="}}`)))
+//	result, _ := MarshalMsgPack(root)
+//	// result == []byte{0x82, 0xa1, 'a', 0x92, 0x01, 0xca, 0xbf, 0xc0, 0x00, 0x00, 0xa1, 'b', 0xc4, 0x02, 0x01, 0x02}
+//
+func MarshalMsgPack(node *Node) ([]byte, error) {
+	return appendMsgPack(make([]byte, 0), node)
+}
+
+// UnmarshalMsgPack decodes the MessagePack value into the node. Binary values become objects {"$binary": "base64"},
+// extensions become objects {"$ext": type, "$binary": "base64"}, keys of maps should be strings or integers.
+// Nesting of values, scalars included, is limited by 10000 levels.
+// Example:
+//
+//	root, _ := UnmarshalMsgPack([]byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0xc4, 0x01, 0xff})
+//	// root.String() == `{"a":1,"b":{"$binary":"/w=="}}`
+//
+func UnmarshalMsgPack(data []byte) (*Node, error) {
+	r := &binaryReader{data: data}
+	node, err := r.msgpack()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.end(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func appendMsgPack(result []byte, node *Node) ([]byte, error) {
+	if node == nil || !node.dirty && !node.ready() {
+		return nil, errorUnparsed()
+	}
+	var err error
+	switch node._type {
+	case Null:
+		result = append(result, 0xc0)
+	case Bool:
+		var value bool
+		if value, err = node.GetBool(); err != nil {
+			return nil, err
+		} else if value {
+			result = append(result, 0xc3)
+		} else {
+			result = append(result, 0xc2)
+		}
+	case Numeric:
+		var value float64
+		if value, err = node.GetNumeric(); err != nil {
+			return nil, err
+		}
+		result = appendMsgPackNumber(result, value)
+	case String:
+		var value string
+		if value, err = node.GetString(); err != nil {
+			return nil, err
+		}
+		result = appendMsgPackHeader(result, len(value), 0xa0, 32, [3]byte{0xd9, 0xda, 0xdb})
+		result = append(result, value...)
+	case Array:
+		result = appendMsgPackHeader(result, len(node.children), 0x90, 16, [3]byte{0, 0xdc, 0xdd})
+		for _, child := range node.children {
+			if result, err = appendMsgPack(result, child); err != nil {
+				return nil, err
+			}
+		}
+	case Object:
+		if data, ok := binaryData(node); ok {
+			result = appendMsgPackHeader(result, len(data), 0, 0, [3]byte{0xc4, 0xc5, 0xc6})
+			return append(result, data...), nil
+		}
+		if _type, data, ok := msgpackExt(node); ok {
+			return appendMsgPackExt(result, _type, data), nil
+		}
+		result = appendMsgPackHeader(result, len(node.children), 0x80, 16, [3]byte{0, 0xde, 0xdf})
+		for _, child := range node.children {
+			if child == nil {
+				return nil, errorUnparsed()
+			}
+			key := child.Key()
+			result = appendMsgPackHeader(result, len(key), 0xa0, 32, [3]byte{0xd9, 0xda, 0xdb})
+			result = append(result, key...)
+			if result, err = appendMsgPack(result, child); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errorType()
+	}
+	return result, nil
+}
+
+// appendMsgPackHeader appends the type and the size of the value: the fixed format if size is less than limit,
+// or one of formats with 8, 16 and 32-bit size; zero code means, that there is no such format
+func appendMsgPackHeader(result []byte, size int, fixed byte, limit int, codes [3]byte) []byte {
+	switch {
+	case size < limit:
+		return append(result, fixed|byte(size))
+	case codes[0] != 0 && size <= math.MaxUint8:
+		return append(result, codes[0], byte(size))
+	case size <= math.MaxUint16:
+		return appendUint(append(result, codes[1]), uint64(size), 2)
+	}
+	return appendUint(append(result, codes[2]), uint64(size), 4)
+}
+
+func appendMsgPackNumber(result []byte, value float64) []byte {
+	switch {
+	case isUnsigned(value):
+		number := uint64(value)
+		switch {
+		case number <= math.MaxInt8:
+			return append(result, byte(number))
+		case number <= math.MaxUint8:
+			return append(result, 0xcc, byte(number))
+		case number <= math.MaxUint16:
+			return appendUint(append(result, 0xcd), number, 2)
+		case number <= math.MaxUint32:
+			return appendUint(append(result, 0xce), number, 4)
+		}
+		return appendUint(append(result, 0xcf), number, 8)
+	case isSigned(value):
+		number := int64(value)
+		switch {
+		case number >= -32:
+			return append(result, byte(number))
+		case number >= math.MinInt8:
+			return append(result, 0xd0, byte(number))
+		case number >= math.MinInt16:
+			return appendUint(append(result, 0xd1), uint64(number), 2)
+		case number >= math.MinInt32:
+			return appendUint(append(result, 0xd2), uint64(number), 4)
+		}
+		return appendUint(append(result, 0xd3), uint64(number), 8)
+	case isFloat32(value):
+		return appendUint(append(result, 0xca), uint64(math.Float32bits(float32(value))), 4)
+	}
+	return appendUint(append(result, 0xcb), math.Float64bits(value), 8)
+}
+
+// msgpackExt returns the type and the data of the object {"$ext": type, "$binary": "base64"}
+func msgpackExt(node *Node) (int8, []byte, bool) {
+	if len(node.children) != 2 {
+		return 0, nil, false
+	}
+	_type, ok := binaryInteger(node, extKey, math.MinInt8, math.MaxInt8)
+	if !ok {
+		return 0, nil, false
+	}
+	data, ok := binaryMember(node)
+	return int8(_type), data, ok
+}
+
+func appendMsgPackExt(result []byte, _type int8, data []byte) []byte {
+	switch len(data) {
+	case 1, 2, 4, 8, 16:
+		code := byte(0xd4)
+		for size := len(data); size > 1; size >>= 1 {
+			code++
+		}
+		result = append(result, code)
+	default:
+		result = appendMsgPackHeader(result, len(data), 0, 0, [3]byte{0xc7, 0xc8, 0xc9})
+	}
+	result = append(result, byte(_type))
+	return append(result, data...)
+}
+
+func (r *binaryReader) msgpack() (*Node, error) {
+	start := r.pos
+	defer r.leave()
+	if err := r.enter(start); err != nil {
+		return nil, err
+	}
+	code, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code <= 0x7f:
+		return NumericNode("", float64(code)), nil
+	case code >= 0xe0:
+		return NumericNode("", float64(int8(code))), nil
+	case code&0xf0 == 0x80:
+		return r.msgpackMap(uint64(code & 0x0f))
+	case code&0xf0 == 0x90:
+		return r.msgpackArray(uint64(code & 0x0f))
+	case code&0xe0 == 0xa0:
+		return r.msgpackString(uint64(code & 0x1f))
+	}
+	var size uint64
+	switch code {
+	case 0xc0:
+		return NullNode(""), nil
+	case 0xc2:
+		return BoolNode("", false), nil
+	case 0xc3:
+		return BoolNode("", true), nil
+	case 0xc4, 0xc5, 0xc6:
+		if size, err = r.uint(1 << (code - 0xc4)); err != nil {
+			return nil, err
+		}
+		data, err := r.next(size)
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode(data), nil
+	case 0xc7, 0xc8, 0xc9:
+		if size, err = r.uint(1 << (code - 0xc7)); err != nil {
+			return nil, err
+		}
+		return r.msgpackExt(size)
+	case 0xca, 0xcb:
+		value, err := r.float(4 << (code - 0xca))
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("", value), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		value, err := r.uint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return NumericNode("", float64(value)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		value, err := r.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// sign extension of the value
+		shift := uint(64 - size*8)
+		return NumericNode("", float64(int64(value<<shift)>>shift)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return r.msgpackExt(1 << (code - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		if size, err = r.uint(1 << (code - 0xd9)); err != nil {
+			return nil, err
+		}
+		return r.msgpackString(size)
+	case 0xdc, 0xdd:
+		if size, err = r.uint(2 << (code - 0xdc)); err != nil {
+			return nil, err
+		}
+		return r.msgpackArray(size)
+	case 0xde, 0xdf:
+		if size, err = r.uint(2 << (code - 0xde)); err != nil {
+			return nil, err
+		}
+		return r.msgpackMap(size)
+	}
+	return nil, errorAt(start, code)
+}
+
+func (r *binaryReader) msgpackString(size uint64) (*Node, error) {
+	data, err := r.next(size)
+	if err != nil {
+		return nil, err
+	}
+	return StringNode("", string(data)), nil
+}
+
+func (r *binaryReader) msgpackArray(size uint64) (*Node, error) {
+	children := make([]*Node, 0)
+	for i := uint64(0); i < size; i++ {
+		child, err := r.msgpack()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return ArrayNode("", children), nil
+}
+
+func (r *binaryReader) msgpackMap(size uint64) (*Node, error) {
+	result := ObjectNode("", nil)
+	for i := uint64(0); i < size; i++ {
+		start := r.pos
+		key, err := r.msgpack()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := binaryKeyOf(key)
+		if !ok {
+			return nil, errorAt(start, r.data[start])
+		}
+		value, err := r.msgpack()
+		if err != nil {
+			return nil, err
+		}
+		if err = result.AppendObject(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// msgpackExt returns the object {"$ext": type, "$binary": "base64"} of the extension
+func (r *binaryReader) msgpackExt(size uint64) (*Node, error) {
+	_type, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.next(size)
+	if err != nil {
+		return nil, err
+	}
+	result := ObjectNode("", nil)
+	_ = result.AppendObject(extKey, NumericNode("", float64(int8(_type))))
+	_ = result.AppendObject(binaryKey, StringNode("", base64.StdEncoding.EncodeToString(data)))
+	return result, nil
+}
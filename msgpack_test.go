@@ -0,0 +1,149 @@
+package ajson
+
+import (
+	"bytes"
+	hexadecimal "encoding/hex"
+	"testing"
+)
+
+func TestMarshalMsgPack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `null`, expected: "c0"},
+		{input: `[true, false]`, expected: "92c3c2"},
+		{input: `[0, 127, 128, 255, 256, 65535, 65536, 4294967296]`, expected: "98007fcc80ccffcd0100cdffffce00010000cf0000000100000000"},
+		{input: `[-1, -32, -33, -128, -129, -32768, -32769, -2147483649]`, expected: "98ffe0d0dfd080d1ff7fd18000d2ffff7fffd3ffffffff7fffffff"},
+		{input: `[-0.0, 1.5, 1.1, 1e300]`, expected: "94ca80000000ca3fc00000cb3ff199999999999acb7e37e43c8800759c"},
+		{input: `"a"`, expected: "a161"},
+		{input: `{"a": [1, -1.5], "b": {"$binary": "AQI="}}`, expected: "82a1619201cabfc00000a162c4020102"},
+		{input: `{"$binary": "wrong"}`, expected: "81a72462696e617279a577726f6e67"},
+		{input: `{"$ext": -1, "$binary": "AQIDBA=="}`, expected: "d6ff01020304"},
+		{input: `{"$ext": 1, "$binary": "AQID"}`, expected: "c70301010203"},
+		{input: `{"$ext": 128, "$binary": ""}`, expected: "82a424657874cc80a72462696e617279a0"},
+		{input: `[[], {}]`, expected: "929080"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result, err := MarshalMsgPack(Must(Unmarshal([]byte(test.input))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if value := hexadecimal.EncodeToString(result); value != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalMsgPack_sizes(t *testing.T) {
+	tests := []struct {
+		size   int
+		prefix string
+	}{
+		{size: 31, prefix: "bf"},
+		{size: 32, prefix: "d920"},
+		{size: 256, prefix: "da0100"},
+		{size: 65536, prefix: "db00010000"},
+	}
+	for _, test := range tests {
+		result, err := MarshalMsgPack(StringNode("", string(make([]byte, test.size))))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if value := hexadecimal.EncodeToString(result[:len(test.prefix)/2]); value != test.prefix {
+			t.Errorf("Wrong prefix for %d: %s, expected: %s", test.size, value, test.prefix)
+		}
+	}
+	children := make([]*Node, 16)
+	for i := range children {
+		children[i] = NullNode("")
+	}
+	if result, err := MarshalMsgPack(ArrayNode("", children)); err != nil || hexadecimal.EncodeToString(result[:3]) != "dc0010" {
+		t.Errorf("Wrong result: %x, %v", result, err)
+	}
+	if _, err := MarshalMsgPack(nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestUnmarshalMsgPack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "c0", expected: `null`},
+		{input: "93c3c2a0", expected: `[true,false,""]`},
+		{input: "98007fcc80ccffcd0100cdffffce00010000cf0000000100000000", expected: `[0,127,128,255,256,65535,65536,4.294967296e+09]`},
+		{input: "98ffe0d0dfd080d1ff7fd18000d2ffff7fffd3ffffffff7fffffff", expected: `[-1,-32,-33,-128,-129,-32768,-32769,-2.147483649e+09]`},
+		{input: "92ca3fc00000cb3ff199999999999a", expected: `[1.5,1.1]`},
+		{input: "93d90161da000162db0000000163", expected: `["a","b","c"]`},
+		{input: "92c5000101c6000000020102", expected: `[{"$binary":"AQ=="},{"$binary":"AQI="}]`},
+		{
+			input:    "94d4010cd5020102d6ff01020304c702010102",
+			expected: `[{"$ext":1,"$binary":"DA=="},{"$ext":2,"$binary":"AQI="},{"$ext":-1,"$binary":"AQIDBA=="},{"$ext":1,"$binary":"AQI="}]`,
+		},
+		{input: "92dc00020102dd0000000103", expected: `[[1,2],[3]]`},
+		{input: "82a161de0001a16201a163df00000001a16402", expected: `{"a":{"b":1},"c":{"d":2}}`},
+		{input: "8201a161ff03", expected: `{"1":"a","-1":3}`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			data, _ := hexadecimal.DecodeString(test.input)
+			root, err := UnmarshalMsgPack(data)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result := root.String(); result != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMsgPack_errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{input: "", err: "unexpected end of file"},
+		{input: "a2", err: "unexpected end of file"},
+		{input: "c4ff", err: "unexpected end of file"},
+		{input: "dfffffffff", err: "unexpected end of file"},
+		{input: "d401", err: "unexpected end of file"},
+		{input: "c1", err: "wrong symbol '\xc1' at 0"},
+		{input: "81c000", err: "wrong symbol '\xc0' at 1"},
+		{input: "0000", err: "wrong symbol '\x00' at 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			data, _ := hexadecimal.DecodeString(test.input)
+			_, err := UnmarshalMsgPack(data)
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMsgPack_depth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		err   string
+	}{
+		{name: "array", input: append(bytes.Repeat([]byte{0x91}, 9999), 0xc0)},
+		{name: "deep array", input: append(bytes.Repeat([]byte{0x91}, 10001), 0xc0), err: "max depth 10000 exceeded at 10000"},
+		{name: "deep map", input: append(bytes.Repeat([]byte{0x81, 0x00}, 10001), 0xc0), err: "max depth 10000 exceeded at 19999"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalMsgPack(test.input)
+			if test.err == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("Wrong error: %v, expected: %s", err, test.err)
+			}
+		})
+	}
+}
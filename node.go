@@ -1,6 +1,7 @@
 package ajson
 
 import (
+	"bytes"
 	"math"
 	"sort"
 	"strconv"
@@ -21,9 +22,13 @@ import (
 //
 // Every type has its own methods to be called.
 // Every Node contains link to a byte data, parent and children, also calculated type of value, atomic value and internal information.
+//
+// Children of the container are stored in the slice: by index for an Array and in order of appearance for an Object,
+// children of an Object are also indexed by their keys.
 type Node struct {
 	parent   *Node
-	children map[string]*Node
+	children []*Node
+	members  map[string]*Node
 	key      *string
 	index    *int
 	_type    NodeType
@@ -31,6 +36,13 @@ type Node struct {
 	borders  [2]int
 	value    atomic.Value
 	dirty    bool
+	origin   *origin
+}
+
+// origin is the source of the parsed node, it is kept after the value of the node was changed
+type origin struct {
+	data    *[]byte
+	borders [2]int
 }
 
 // NodeType is a kind of reflection of JSON type to a type of golang
@@ -105,40 +117,37 @@ func BoolNode(key string, value bool) (current *Node) {
 // ArrayNode is constructor for Node with an Array value
 func ArrayNode(key string, value []*Node) (current *Node) {
 	current = &Node{
-		data:  nil,
-		_type: Array,
-		key:   &key,
-		dirty: true,
+		data:     nil,
+		_type:    Array,
+		key:      &key,
+		dirty:    true,
+		children: make([]*Node, 0, len(value)),
 	}
-	current.children = make(map[string]*Node, len(value))
-	if value != nil {
-		current.value.Store(value)
-		for i, val := range value {
-			var index = i
-			current.children[strconv.Itoa(i)] = val
-			val.parent = current
-			val.index = &index
-		}
+	for i, val := range value {
+		var index = i
+		current.children = append(current.children, val)
+		val.parent = current
+		val.index = &index
 	}
 	return
 }
 
-// ObjectNode is constructor for Node with an Object value
+// ObjectNode is constructor for Node with an Object value, children will be ordered by keys
 func ObjectNode(key string, value map[string]*Node) (current *Node) {
 	current = &Node{
 		_type:    Object,
 		key:      &key,
-		children: value,
+		children: make([]*Node, 0, len(value)),
+		members:  make(map[string]*Node, len(value)),
 		dirty:    true,
 	}
-	if value != nil {
-		current.value.Store(value)
-		for key, val := range value {
-			val.parent = current
-			val.key = &key
-		}
-	} else {
-		current.children = make(map[string]*Node)
+	for _, key := range sortedKeys(value) {
+		key := key
+		val := value[key]
+		current.children = append(current.children, val)
+		current.members[key] = val
+		val.parent = current
+		val.key = &key
 	}
 	return
 }
@@ -152,19 +161,19 @@ func newNode(parent *Node, buf *buffer, _type NodeType, key **string) (current *
 		key:     *key,
 		dirty:   false,
 	}
-	if _type == Object || _type == Array {
-		current.children = make(map[string]*Node)
+	if _type == Object {
+		current.members = make(map[string]*Node)
 	}
 	if parent != nil {
 		if parent.IsArray() {
 			size := len(parent.children)
 			current.index = &size
-			parent.children[strconv.Itoa(size)] = current
+			parent.children = append(parent.children, current)
 		} else if parent.IsObject() {
 			if *key == nil {
 				err = errorSymbol(buf)
 			} else {
-				parent.children[**key] = current
+				parent.setMember(**key, current)
 				*key = nil
 			}
 		} else {
@@ -208,6 +217,53 @@ func (n *Node) Source() []byte {
 	return nil
 }
 
+// Span returns the borders of the node in the source data: offset of the first byte and offset after the last one.
+// For the changed node it returns the borders of the source value, that was replaced.
+// Returns (-1, -1) if the node has no source, e.g. it was created by constructor.
+func (n *Node) Span() (start, end int) {
+	if n == nil {
+		return -1, -1
+	}
+	data, borders := n.span()
+	if data == nil || borders[1] == 0 {
+		return -1, -1
+	}
+	return borders[0], borders[1]
+}
+
+// Position returns the line and the column of the first byte of the node in the source data, both are 1-based.
+// Returns (0, 0) if the node has no source. Check Span for the details.
+// Example:
+//
+//	nodes, _ := root.JSONPath("$..price")
+//	for _, node := range nodes {
+//		line, col := node.Position()
+//		fmt.Printf("config.json:%d:%d\n", line, col)
+//	}
+//
+func (n *Node) Position() (line, col int) {
+	start, _ := n.Span()
+	if start == -1 {
+		return 0, 0
+	}
+	data, _ := n.span()
+	return OffsetPosition(*data, start)
+}
+
+// OffsetPosition returns 1-based line and column of the byte on the given offset. Columns are counted in bytes,
+// lines are separated by '\n'.
+func OffsetPosition(data []byte, offset int) (line, col int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	line = bytes.Count(data[:offset], []byte{skipN}) + 1
+	col = offset - bytes.LastIndexByte(data[:offset], skipN)
+	return line, col
+}
+
 // String is implementation of Stringer interface, returns string based on source part
 func (n *Node) String() string {
 	if n == nil {
@@ -223,6 +279,18 @@ func (n *Node) String() string {
 	return string(val)
 }
 
+// StringWithOptions returns the marshaled value of the node with the given options, or an error message
+func (n *Node) StringWithOptions(options MarshalOptions) string {
+	if n == nil {
+		return ""
+	}
+	val, err := MarshalWithOptions(n, options)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return string(val)
+}
+
 // Type will return type of current node
 func (n *Node) Type() NodeType {
 	if n == nil {
@@ -261,14 +329,16 @@ func (n *Node) Size() int {
 	return len(n.children)
 }
 
-// Keys will return count all keys of children of current node, please check, that parent of this node has an Object type
+// Keys will return all keys of children of current node in order of appearance, please check, that current node has an Object type
 func (n *Node) Keys() (result []string) {
 	if n == nil {
 		return nil
 	}
-	result = make([]string, 0, len(n.children))
-	for key := range n.children {
-		result = append(result, key)
+	result = make([]string, 0, len(n.members))
+	for _, child := range n.children {
+		if child.key != nil {
+			result = append(result, *child.key)
+		}
 	}
 	return
 }
@@ -337,7 +407,7 @@ func (n *Node) IsBool() bool {
 //
 // BUT! Current method doesn't calculate underlying nodes (use method Node.Unpack for that).
 //
-// Value will be calculated only once and saved into atomic.Value.
+// Value of the scalar node will be calculated only once and saved into atomic.Value, containers return a new slice or map on each call.
 func (n *Node) Value() (value interface{}, err error) {
 	if n == nil {
 		return nil, errorUnparsed()
@@ -387,18 +457,14 @@ func (n *Node) getValue() (value interface{}, err error) {
 			n.value.Store(value)
 		case Array:
 			children := make([]*Node, len(n.children))
-			for _, child := range n.children {
-				children[*child.index] = child
-			}
+			copy(children, n.children)
 			value = children
-			n.value.Store(value)
 		case Object:
-			result := make(map[string]*Node)
-			for key, child := range n.children {
+			result := make(map[string]*Node, len(n.members))
+			for key, child := range n.members {
 				result[key] = child
 			}
 			value = result
-			n.value.Store(value)
 		}
 	}
 	return
@@ -589,17 +655,17 @@ func (n *Node) Unpack() (value interface{}, err error) {
 		}
 	case Array:
 		children := make([]interface{}, len(n.children))
-		for _, child := range n.children {
+		for i, child := range n.children {
 			val, err := child.Unpack()
 			if err != nil {
 				return nil, err
 			}
-			children[*child.index] = val
+			children[i] = val
 		}
 		value = children
 	case Object:
-		result := make(map[string]interface{})
-		for key, child := range n.children {
+		result := make(map[string]interface{}, len(n.members))
+		for key, child := range n.members {
 			result[key], err = child.Unpack()
 			if err != nil {
 				return nil, err
@@ -621,11 +687,10 @@ func (n *Node) GetIndex(index int) (*Node, error) {
 	if index < 0 {
 		index += len(n.children)
 	}
-	child, ok := n.children[strconv.Itoa(index)]
-	if !ok {
+	if index < 0 || index >= len(n.children) {
 		return nil, errorRequest("out of index %d", index)
 	}
-	return child, nil
+	return n.children[index], nil
 }
 
 // MustIndex will return child node of current array node. If current node is not Array, or index is unavailable, raise a panic
@@ -645,7 +710,7 @@ func (n *Node) GetKey(key string) (*Node, error) {
 	if n._type != Object {
 		return nil, errorType()
 	}
-	value, ok := n.children[key]
+	value, ok := n.members[key]
 	if !ok {
 		return nil, errorRequest("wrong key '%s'", key)
 	}
@@ -666,7 +731,7 @@ func (n *Node) HasKey(key string) bool {
 	if n == nil {
 		return false
 	}
-	_, ok := n.children[key]
+	_, ok := n.members[key]
 	return ok
 }
 
@@ -678,7 +743,8 @@ func (n *Node) Empty() bool {
 	return len(n.children) == 0
 }
 
-// Path returns full JsonPath of current Node
+// Path returns full JsonPath of current Node: keys are written in brackets and single quotes, quotes and backslashes
+// of keys are escaped with '\', so the path can be evaluated again: $['it\'s'][0]
 func (n *Node) Path() string {
 	if n == nil {
 		return ""
@@ -687,7 +753,7 @@ func (n *Node) Path() string {
 		return "$"
 	}
 	if n.key != nil {
-		return n.parent.Path() + "['" + n.Key() + "']"
+		return n.parent.Path() + "['" + escapeJSONPathKey(n.Key()) + "']"
 	}
 	return n.parent.Path() + "[" + strconv.Itoa(n.Index()) + "]"
 }
@@ -914,22 +980,42 @@ func (n *Node) Inheritors() (result []*Node) {
 	size := len(n.children)
 	if n.IsObject() {
 		result = make([]*Node, size)
-		keys := n.Keys()
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i] < keys[j]
-		})
-		for i, key := range keys {
-			result[i] = n.children[key]
+		for i, key := range sortedKeys(n.members) {
+			result[i] = n.members[key]
 		}
 	} else if n.IsArray() {
 		result = make([]*Node, size)
-		for _, element := range n.children {
-			result[*element.index] = element
-		}
+		copy(result, n.children)
 	}
 	return
 }
 
+// EachIndex calls fn for each child of current Array node in order of indexes, while fn returns true.
+// In contrast to GetArray and Inheritors, it doesn't allocate memory.
+func (n *Node) EachIndex(fn func(index int, child *Node) bool) {
+	if !n.IsArray() {
+		return
+	}
+	for i, child := range n.children {
+		if !fn(i, child) {
+			return
+		}
+	}
+}
+
+// EachKey calls fn for each child of current Object node in order of appearance, while fn returns true.
+// In contrast to GetObject and Inheritors, it doesn't allocate memory.
+func (n *Node) EachKey(fn func(key string, child *Node) bool) {
+	if !n.IsObject() {
+		return
+	}
+	for _, child := range n.children {
+		if !fn(*child.key, child) {
+			return
+		}
+	}
+}
+
 // JSONPath evaluate path for current node
 func (n *Node) JSONPath(path string) (result []*Node, err error) {
 	commands, err := ParseJSONPath(path)
@@ -939,6 +1025,44 @@ func (n *Node) JSONPath(path string) (result []*Node, err error) {
 	return ApplyJSONPath(n, commands)
 }
 
+// getChild returns child of the container node by key, or by string representation of the index for an Array
+func (n *Node) getChild(key string) (*Node, bool) {
+	if n.IsArray() {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(n.children) {
+			return nil, false
+		}
+		return n.children[index], true
+	}
+	child, ok := n.members[key]
+	return child, ok
+}
+
+// setMember sets child of current Object node by key, existing child will be replaced in place
+func (n *Node) setMember(key string, value *Node) {
+	if old, ok := n.members[key]; ok {
+		for i, child := range n.children {
+			if child == old {
+				n.children[i] = value
+				break
+			}
+		}
+	} else {
+		n.children = append(n.children, value)
+	}
+	n.members[key] = value
+}
+
+// sortedKeys returns sorted keys of the map
+func sortedKeys(value map[string]*Node) []string {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // root returns the root node
 func (n *Node) root() (node *Node) {
 	node = n
@@ -1,7 +1,6 @@
 package ajson
 
 import (
-	"strconv"
 	"sync/atomic"
 )
 
@@ -81,6 +80,8 @@ func (n *Node) SetNode(value *Node) error {
 	node := value.Clone()
 	node.setReference(n.parent, n.key, n.index)
 	n.setReference(nil, nil, nil)
+	n.keepOrigin()
+	node.origin = n.origin
 	*n = *node
 	if n.parent != nil {
 		n.parent.mark()
@@ -173,18 +174,29 @@ func (n *Node) Clone() *Node {
 
 func (n *Node) clone() *Node {
 	node := &Node{
-		parent:   n.parent,
-		children: make(map[string]*Node, len(n.children)),
-		key:      n.key,
-		index:    n.index,
-		_type:    n._type,
-		data:     n.data,
-		borders:  n.borders,
-		value:    n.value,
-		dirty:    n.dirty,
-	}
-	for key, value := range n.children {
-		node.children[key] = value.clone()
+		parent:  n.parent,
+		key:     n.key,
+		index:   n.index,
+		_type:   n._type,
+		data:    n.data,
+		borders: n.borders,
+		value:   n.value,
+		dirty:   n.dirty,
+		origin:  n.origin,
+	}
+	if n.children != nil {
+		node.children = make([]*Node, 0, len(n.children))
+	}
+	if n.members != nil {
+		node.members = make(map[string]*Node, len(n.members))
+	}
+	for _, value := range n.children {
+		child := value.clone()
+		child.parent = node
+		node.children = append(node.children, child)
+		if child.key != nil && node.members != nil {
+			node.members[*child.key] = child
+		}
 	}
 	return node
 }
@@ -202,26 +214,29 @@ func (n *Node) update(_type NodeType, value interface{}) error {
 
 	atomic.StoreInt32((*int32)(&n._type), int32(_type))
 	n.value = atomic.Value{}
-	if value != nil {
-		switch _type {
-		case Array:
-			nodes := value.([]*Node)
-			n.children = make(map[string]*Node, len(nodes))
-			for _, node := range nodes {
-				if err = n.appendNode(nil, node); err != nil {
-					return err
-				}
+	switch _type {
+	case Array:
+		nodes, _ := value.([]*Node)
+		n.children = make([]*Node, 0, len(nodes))
+		for _, node := range nodes {
+			if err = n.appendNode(nil, node); err != nil {
+				return err
 			}
-		case Object:
-			nodes := value.(map[string]*Node)
-			n.children = make(map[string]*Node, len(nodes))
-			for key, node := range nodes {
-				if err = n.appendNode(&key, node); err != nil {
-					return err
-				}
+		}
+	case Object:
+		nodes, _ := value.(map[string]*Node)
+		n.children = make([]*Node, 0, len(nodes))
+		n.members = make(map[string]*Node, len(nodes))
+		for _, key := range sortedKeys(nodes) {
+			key := key
+			if err = n.appendNode(&key, nodes[key]); err != nil {
+				return err
 			}
 		}
-		n.value.Store(value)
+	default:
+		if value != nil {
+			n.value.Store(value)
+		}
 	}
 	return nil
 }
@@ -274,10 +289,15 @@ func (n *Node) remove(value *Node) error {
 	}
 	n.mark()
 	if n.IsArray() {
-		delete(n.children, strconv.Itoa(*value.index))
 		n.dropindex(*value.index)
 	} else {
-		delete(n.children, *value.key)
+		delete(n.members, *value.key)
+		for i, child := range n.children {
+			if child == value {
+				n.children = append(n.children[:i], n.children[i+1:]...)
+				break
+			}
+		}
 	}
 	value.parent = nil
 	return nil
@@ -285,13 +305,12 @@ func (n *Node) remove(value *Node) error {
 
 // dropindex: internal method to reindexing current array value
 func (n *Node) dropindex(index int) {
-	for i := index + 1; i <= len(n.children); i++ {
-		previous := i - 1
-		if current, ok := n.children[strconv.Itoa(i)]; ok {
-			current.index = &previous
-			n.children[strconv.Itoa(previous)] = current
-		}
-		delete(n.children, strconv.Itoa(i))
+	copy(n.children[index:], n.children[index+1:])
+	n.children[len(n.children)-1] = nil
+	n.children = n.children[:len(n.children)-1]
+	for i := index; i < len(n.children); i++ {
+		current := i
+		n.children[i].index = &current
 	}
 }
 
@@ -308,18 +327,14 @@ func (n *Node) appendNode(key *string, value *Node) error {
 	value.parent = n
 	value.key = key
 	if key != nil {
-		if old, ok := n.children[*key]; ok {
-			if old != value {
-				if err := n.remove(old); err != nil {
-					return err
-				}
-			}
+		if old, ok := n.members[*key]; ok && old != value {
+			old.parent = nil
 		}
-		n.children[*key] = value
+		n.setMember(*key, value)
 	} else {
 		index := len(n.children)
 		value.index = &index
-		n.children[strconv.Itoa(index)] = value
+		n.children = append(n.children, value)
 	}
 	return nil
 }
@@ -335,12 +350,29 @@ func (n *Node) mark() {
 
 // clear current value of node
 func (n *Node) clear() {
+	n.keepOrigin()
 	n.data = nil
 	n.borders[1] = 0
-	for key := range n.children {
-		n.children[key].parent = nil
+	for _, child := range n.children {
+		child.parent = nil
 	}
 	n.children = nil
+	n.members = nil
+}
+
+// keepOrigin stores the source of the parsed node, before its value will be changed
+func (n *Node) keepOrigin() {
+	if n.origin == nil && n.data != nil && n.ready() {
+		n.origin = &origin{data: n.data, borders: n.borders}
+	}
+}
+
+// span returns the data and borders of the parsed node, also for the changed one
+func (n *Node) span() (*[]byte, [2]int) {
+	if n.origin != nil {
+		return n.origin.data, n.origin.borders
+	}
+	return n.data, n.borders
 }
 
 // isParentOrSelfNode check if current node is the same as given one of parents
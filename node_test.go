@@ -3,8 +3,10 @@ package ajson
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"math"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -748,6 +750,81 @@ func TestNode_Source(t *testing.T) {
 	}
 }
 
+func TestNode_Span(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"foo": [1, "bar"]}`)))
+	tests := []struct {
+		name       string
+		node       *Node
+		start, end int
+	}{
+		{name: "root", node: root, start: 0, end: 19},
+		{name: "foo", node: root.MustKey("foo"), start: 8, end: 18},
+		{name: "bar", node: root.MustKey("foo").MustIndex(1), start: 12, end: 17},
+		{name: "constructor", node: NumericNode("", 1), start: -1, end: -1},
+		{name: "nil", node: nil, start: -1, end: -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if start, end := test.node.Span(); start != test.start || end != test.end {
+				t.Errorf("Wrong span: %d:%d, expected: %d:%d", start, end, test.start, test.end)
+			}
+		})
+	}
+
+	changed := root.MustKey("foo").MustIndex(0)
+	_ = changed.SetString("changed")
+	if start, end := changed.Span(); start != 9 || end != 10 {
+		t.Errorf("Wrong span of the changed node: %d:%d", start, end)
+	}
+}
+
+func TestNode_Position(t *testing.T) {
+	root := Must(Unmarshal([]byte("{\n  \"foo\": [\r\n    1,\n\t\"bar\"\n  ]\n}")))
+	tests := []struct {
+		name      string
+		node      *Node
+		line, col int
+	}{
+		{name: "root", node: root, line: 1, col: 1},
+		{name: "foo", node: root.MustKey("foo"), line: 2, col: 10},
+		{name: "1", node: root.MustKey("foo").MustIndex(0), line: 3, col: 5},
+		{name: "bar", node: root.MustKey("foo").MustIndex(1), line: 4, col: 2},
+		{name: "constructor", node: NullNode(""), line: 0, col: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if line, col := test.node.Position(); line != test.line || col != test.col {
+				t.Errorf("Wrong position: %d:%d, expected: %d:%d", line, col, test.line, test.col)
+			}
+		})
+	}
+}
+
+func TestOffsetPosition(t *testing.T) {
+	data := []byte("ab\ncd\n\nё")
+	tests := []struct {
+		offset    int
+		line, col int
+	}{
+		{offset: 0, line: 1, col: 1},
+		{offset: 1, line: 1, col: 2},
+		{offset: 2, line: 1, col: 3},
+		{offset: 3, line: 2, col: 1},
+		{offset: 6, line: 3, col: 1},
+		{offset: 7, line: 4, col: 1},
+		{offset: 9, line: 4, col: 3},
+		{offset: 100, line: 4, col: 3},
+		{offset: -1, line: 1, col: 1},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.offset), func(t *testing.T) {
+			if line, col := OffsetPosition(data, test.offset); line != test.line || col != test.col {
+				t.Errorf("Wrong position: %d:%d, expected: %d:%d", line, col, test.line, test.col)
+			}
+		})
+	}
+}
+
 func TestNode_String(t *testing.T) {
 	root, err := Unmarshal([]byte(`{"foo":true,"bar":null}`))
 	if err != nil {
@@ -782,6 +859,27 @@ func TestNode_String(t *testing.T) {
 	}
 }
 
+func TestNode_StringWithOptions(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": "<ключ>"}`)))
+	if value := root.StringWithOptions(MarshalOptions{}); value != `{"a": "<ключ>"}` {
+		t.Errorf("Wrong value: %s", value)
+	}
+	if value := root.StringWithOptions(MarshalOptions{ASCIIOnly: true}); value != `{"a":"\u003c\u043a\u043b\u044e\u0447\u003e"}` {
+		t.Errorf("Wrong value: %s", value)
+	}
+	if value := StringNode("", "<a>").StringWithOptions(MarshalOptions{DisableHTMLEscape: true}); value != `"<a>"` {
+		t.Errorf("Wrong value: %s", value)
+	}
+	if value := (*Node)(nil).StringWithOptions(MarshalOptions{}); value != "" {
+		t.Errorf("Wrong value: %s", value)
+	}
+	broken := ArrayNode("", nil)
+	broken.children = append(broken.children, nil)
+	if value := broken.StringWithOptions(MarshalOptions{}); value != "Error: not parsed yet" {
+		t.Errorf("Wrong value: %s", value)
+	}
+}
+
 func TestNode_Type(t *testing.T) {
 	tests := []struct {
 		_type NodeType
@@ -868,6 +966,34 @@ func TestNode_Path(t *testing.T) {
 	}
 }
 
+func TestNode_Path_escaped(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{name: "quote", key: `a'b`, expected: `$['a\'b'][0]`},
+		{name: "dot", key: `a.b`, expected: `$['a.b'][0]`},
+		{name: "bracket", key: `a]b`, expected: `$['a]b'][0]`},
+		{name: "backslash", key: `a\b`, expected: `$['a\\b'][0]`},
+		{name: "all", key: `['.\']`, expected: `$['[\'.\\\']'][0]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := ObjectNode("", map[string]*Node{test.key: Must(Unmarshal([]byte(`[1]`)))})
+			node := root.MustKey(test.key).MustIndex(0)
+			if result := node.Path(); result != test.expected {
+				t.Errorf("Wrong path: %s, expected: %s", result, test.expected)
+			}
+			if result, err := root.JSONPath(node.Path()); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if len(result) != 1 || result[0] != node {
+				t.Errorf("Wrong result of the path: %v", result)
+			}
+		})
+	}
+}
+
 func TestNode_Eq(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1671,6 +1797,23 @@ func TestObjectNode(t *testing.T) {
 	}
 }
 
+func TestObjectNode_keys(t *testing.T) {
+	node := ObjectNode("", map[string]*Node{
+		"a": NumericNode("", 1),
+		"b": NumericNode("", 2),
+		"c": NumericNode("", 3),
+	})
+	if keys := node.Keys(); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Wrong keys: %v", keys)
+	}
+	result, err := Marshal(node)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if string(result) != `{"a":1,"b":2,"c":3}` {
+		t.Errorf("Wrong result: %s", result)
+	}
+}
+
 func TestNode_Inheritors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1943,8 +2086,8 @@ func TestNode_Value(t *testing.T) {
 			name: "array",
 			node: array,
 			wantValue: []*Node{
-				array.children["0"],
-				array.children["1"],
+				array.children[0],
+				array.children[1],
 			},
 			wantErr: false,
 		},
@@ -1958,8 +2101,8 @@ func TestNode_Value(t *testing.T) {
 			name: "object",
 			node: object,
 			wantValue: map[string]*Node{
-				"foo": object.children["foo"],
-				"bar": object.children["bar"],
+				"foo": object.members["foo"],
+				"bar": object.members["bar"],
 			},
 			wantErr: false,
 		},
@@ -1997,3 +2140,115 @@ func TestNode_Value(t *testing.T) {
 		})
 	}
 }
+
+func TestNode_EachIndex(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1, "a", null, {}]`)))
+	result := make([]string, 0)
+	root.EachIndex(func(index int, child *Node) bool {
+		result = append(result, fmt.Sprintf("%d:%s", index, child))
+		return index < 2
+	})
+	if strings.Join(result, " ") != `0:1 1:"a" 2:null` {
+		t.Errorf("Wrong result: %v", result)
+	}
+	root.MustIndex(3).EachIndex(func(index int, child *Node) bool {
+		t.Errorf("Unexpected call for an object")
+		return true
+	})
+	(*Node)(nil).EachIndex(func(index int, child *Node) bool {
+		t.Errorf("Unexpected call for nil")
+		return true
+	})
+}
+
+func TestNode_EachKey(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"c": 1, "a": "a", "b": null, "d": []}`)))
+	result := make([]string, 0)
+	root.EachKey(func(key string, child *Node) bool {
+		result = append(result, fmt.Sprintf("%s:%s", key, child))
+		return key != "b"
+	})
+	if strings.Join(result, " ") != `c:1 a:"a" b:null` {
+		t.Errorf("Wrong result: %v", result)
+	}
+	root.MustKey("d").EachKey(func(key string, child *Node) bool {
+		t.Errorf("Unexpected call for an array")
+		return true
+	})
+	(*Node)(nil).EachKey(func(key string, child *Node) bool {
+		t.Errorf("Unexpected call for nil")
+		return true
+	})
+}
+
+func TestNode_Each_allocations(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"array": [1, 2, 3, 4, 5], "object": {"a": 1, "b": 2, "c": 3}}`)))
+	array, object := root.MustKey("array"), root.MustKey("object")
+	count := 0
+	onIndex := func(index int, child *Node) bool {
+		count++
+		return true
+	}
+	onKey := func(key string, child *Node) bool {
+		count++
+		return true
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		array.EachIndex(onIndex)
+		object.EachKey(onKey)
+	})
+	if allocs != 0 {
+		t.Errorf("Unexpected allocations: %v", allocs)
+	}
+	if count != 101*8 {
+		t.Errorf("Wrong count of calls: %d", count)
+	}
+}
+
+func TestNode_Keys_order(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"c": 1, "a": 2, "b": 3, "a": 4}`)))
+	if keys := strings.Join(root.Keys(), ","); keys != "c,a,b" {
+		t.Errorf("Wrong keys order: %s", keys)
+	}
+	if value := root.MustKey("a").MustNumeric(); value != 4 {
+		t.Errorf("Wrong value of duplicated key: %v", value)
+	}
+	_ = root.AppendObject("c", NumericNode("", 5))
+	_ = root.AppendObject("d", NumericNode("", 6))
+	_ = root.DeleteKey("a")
+	if keys := strings.Join(root.Keys(), ","); keys != "c,b,d" {
+		t.Errorf("Wrong keys order: %s", keys)
+	}
+	if result := root.String(); result != `{"c":5,"b":3,"d":6}` {
+		t.Errorf("Wrong result: %s", result)
+	}
+}
+
+func TestNode_GetArray_actual(t *testing.T) {
+	root := Must(Unmarshal([]byte(`[1]`)))
+	if size := len(root.MustArray()); size != 1 {
+		t.Errorf("Wrong size: %d", size)
+	}
+	_ = root.AppendArray(NullNode(""))
+	if size := len(root.MustArray()); size != 2 {
+		t.Errorf("Wrong size after append: %d", size)
+	}
+}
+
+func BenchmarkNode_EachIndex(b *testing.B) {
+	root := Must(Unmarshal([]byte(`[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root.EachIndex(func(index int, child *Node) bool {
+			return true
+		})
+	}
+}
+
+func BenchmarkNode_Inheritors(b *testing.B) {
+	root := Must(Unmarshal([]byte(`[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = root.Inheritors()
+	}
+}
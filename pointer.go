@@ -0,0 +1,205 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// JSON Pointer described at https://tools.ietf.org/html/rfc6901
+//
+// A JSON Pointer is a string of zero or more reference tokens, each prefixed by a '/' character:
+//
+//    ""           the whole document
+//    "/foo"       the member "foo" of the root object
+//    "/foo/0"     the first element of the array "foo"
+//    "/a~1b"      the member "a/b" of the root object
+//    "/m~0n"      the member "m~n" of the root object
+//
+// Characters '~' and '/' of the keys are encoded as "~0" and "~1" respectively.
+
+// ParsePointer will parse current JSON Pointer and return all unescaped reference tokens.
+// Example:
+//
+// 	result, _ := ParsePointer("/store/book/0/a~1b")
+// 	result == []string{"store", "book", "0", "a/b"}
+//
+func ParsePointer(pointer string) (result []string, err error) {
+	result = make([]string, 0)
+	if pointer == "" {
+		return
+	}
+	if pointer[0] != '/' {
+		return nil, errorRequest("wrong pointer: '%s' must start with '/'", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token, err = unescapePointer(token)
+		if err != nil {
+			return nil, errorRequest("wrong pointer: '%s'", pointer)
+		}
+		result = append(result, token)
+	}
+	return
+}
+
+// Pointer returns the node referenced by JSON Pointer. Current node is used as the document root.
+func (n *Node) Pointer(pointer string) (*Node, error) {
+	if n == nil {
+		return nil, errorUnparsed()
+	}
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := n
+	for _, token := range tokens {
+		switch node.Type() {
+		case Object:
+			node, err = node.GetKey(token)
+		case Array:
+			index, ok := pointerIndex(token)
+			if !ok {
+				return nil, errorRequest("wrong pointer: '%s' is not an index of array", token)
+			}
+			node, err = node.GetIndex(index)
+		default:
+			return nil, errorRequest("wrong pointer: '%s' is not a container", node.Path())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// PointerPath returns full JSON Pointer of current Node
+func (n *Node) PointerPath() string {
+	if n == nil || n.parent == nil {
+		return ""
+	}
+	if n.key != nil {
+		return n.parent.PointerPath() + "/" + escapePointer(n.Key())
+	}
+	return n.parent.PointerPath() + "/" + strconv.Itoa(n.Index())
+}
+
+// PointerToJSONPath converts JSON Pointer to the normalized JSONPath in bracket–notation.
+// Tokens, that looks like an array index, will be converted to `[0]`, all others to `['key']`.
+// Example:
+//
+// 	result, _ := PointerToJSONPath("/store/book/0/a~1b")
+// 	result == "$['store']['book'][0]['a/b']"
+//
+func PointerToJSONPath(pointer string) (string, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	result := "$"
+	for _, token := range tokens {
+		if _, ok := pointerIndex(token); ok {
+			result += "[" + token + "]"
+		} else {
+			result += "['" + escapeJSONPathKey(token) + "']"
+		}
+	}
+	return result, nil
+}
+
+// JSONPathToPointer converts JSONPath to the JSON Pointer.
+// Only definite paths are available: with the root element, keys and non-negative indexes.
+// Example:
+//
+// 	result, _ := JSONPathToPointer("$.store.book[0]['a/b']")
+// 	result == "/store/book/0/a~1b"
+//
+func JSONPathToPointer(path string) (string, error) {
+	commands, err := ParseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	if len(commands) == 0 || commands[0] != "$" {
+		return "", errorRequest("JSONPath '%s' should start with the root element", path)
+	}
+	result := ""
+	for _, cmd := range commands[1:] {
+		var key string
+		switch {
+		case cmd == "$" || cmd == "@" || cmd == ".." || cmd == "*" || cmd == "":
+			return "", errorRequest("JSONPath '%s' is not definite", path)
+		case cmd[0] == quote || cmd[0] == quotes:
+			var ok bool
+			if key, ok = str(cmd); !ok || len(cmd) < 2 || cmd[0] != cmd[len(cmd)-1] {
+				return "", errorRequest("wrong key %s in JSONPath '%s'", cmd, path)
+			}
+		default:
+			tokens, err := tokenize(cmd)
+			if err != nil {
+				return "", err
+			}
+			if tokens.exists(":") || tokens.exists(",") || strings.HasPrefix(cmd, "?(") || strings.HasPrefix(cmd, "(") {
+				return "", errorRequest("JSONPath '%s' is not definite", path)
+			}
+			if index, err := strconv.Atoi(cmd); err == nil && index < 0 {
+				return "", errorRequest("JSONPath '%s' is not definite", path)
+			}
+			key = cmd
+		}
+		result += "/" + escapePointer(key)
+	}
+	return result, nil
+}
+
+// pointerIndex returns array index from the reference token, leading zeros are not allowed
+func pointerIndex(token string) (int, bool) {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
+
+func escapePointer(token string) string {
+	if strings.IndexAny(token, "~/") == -1 {
+		return token
+	}
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+func unescapePointer(token string) (string, error) {
+	if strings.IndexByte(token, '~') == -1 {
+		return token, nil
+	}
+	result := make([]byte, 0, len(token))
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			result = append(result, token[i])
+			continue
+		}
+		if i+1 >= len(token) {
+			return "", errorRequest("wrong escape sequence in '%s'", token)
+		}
+		i++
+		switch token[i] {
+		case '0':
+			result = append(result, '~')
+		case '1':
+			result = append(result, '/')
+		default:
+			return "", errorRequest("wrong escape sequence in '%s'", token)
+		}
+	}
+	return string(result), nil
+}
+
+// escapeJSONPathKey escapes key to be used in bracket–notation: `['key']`
+func escapeJSONPathKey(key string) string {
+	if strings.IndexAny(key, `\'`) == -1 {
+		return key
+	}
+	return strings.Replace(strings.Replace(key, `\`, `\\`, -1), `'`, `\'`, -1)
+}
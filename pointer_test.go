@@ -0,0 +1,223 @@
+package ajson
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Example from https://tools.ietf.org/html/rfc6901#section-5
+var pointerTestData = []byte(`{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`)
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		expected []string
+		err      bool
+	}{
+		{pointer: "", expected: []string{}},
+		{pointer: "/", expected: []string{""}},
+		{pointer: "/foo/0", expected: []string{"foo", "0"}},
+		{pointer: "/a~1b/m~0n", expected: []string{"a/b", "m~n"}},
+		{pointer: "/~01", expected: []string{"~1"}},
+		{pointer: "/~10", expected: []string{"/0"}},
+		{pointer: "foo", err: true},
+		{pointer: "/foo~", err: true},
+		{pointer: "/foo~2", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			result, err := ParsePointer(test.pointer)
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error, got: %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Wrong result: %#v, expected: %#v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_Pointer(t *testing.T) {
+	root := Must(Unmarshal(pointerTestData))
+	tests := []struct {
+		pointer  string
+		expected string
+		err      bool
+	}{
+		{pointer: "", expected: string(pointerTestData)},
+		{pointer: "/foo", expected: `["bar", "baz"]`},
+		{pointer: "/foo/0", expected: `"bar"`},
+		{pointer: "/", expected: `0`},
+		{pointer: "/a~1b", expected: `1`},
+		{pointer: "/c%d", expected: `2`},
+		{pointer: "/e^f", expected: `3`},
+		{pointer: "/g|h", expected: `4`},
+		{pointer: "/i\\j", expected: `5`},
+		{pointer: "/k\"l", expected: `6`},
+		{pointer: "/ ", expected: `7`},
+		{pointer: "/m~0n", expected: `8`},
+		{pointer: "/foo/2", err: true},
+		{pointer: "/foo/-", err: true},
+		{pointer: "/foo/01", err: true},
+		{pointer: "/foo/0/bar", err: true},
+		{pointer: "/bar", err: true},
+		{pointer: "bar", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			result, err := root.Pointer(test.pointer)
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error, got: %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result.String() != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestNode_Pointer_nil(t *testing.T) {
+	if _, err := (*Node)(nil).Pointer(""); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestNode_PointerPath(t *testing.T) {
+	root := Must(Unmarshal(pointerTestData))
+	tests := []struct {
+		pointer string
+	}{
+		{pointer: ""},
+		{pointer: "/foo"},
+		{pointer: "/foo/1"},
+		{pointer: "/"},
+		{pointer: "/a~1b"},
+		{pointer: "/m~0n"},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			node, err := root.Pointer(test.pointer)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if node.PointerPath() != test.pointer {
+				t.Errorf("Wrong result: %s, expected: %s", node.PointerPath(), test.pointer)
+			}
+		})
+	}
+	if (*Node)(nil).PointerPath() != "" {
+		t.Errorf("Wrong (nil).PointerPath()")
+	}
+}
+
+func TestPointerToJSONPath(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		expected string
+		err      bool
+	}{
+		{pointer: "", expected: "$"},
+		{pointer: "/foo/0", expected: "$['foo'][0]"},
+		{pointer: "/foo/01", expected: "$['foo']['01']"},
+		{pointer: "/a~1b/m~0n", expected: "$['a/b']['m~n']"},
+		{pointer: "/it's", expected: `$['it\'s']`},
+		{pointer: "foo", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.pointer, func(t *testing.T) {
+			result, err := PointerToJSONPath(test.pointer)
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error, got: %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestJSONPathToPointer(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		err      bool
+	}{
+		{path: "$", expected: ""},
+		{path: "$.foo[0]", expected: "/foo/0"},
+		{path: "$['foo'][1]", expected: "/foo/1"},
+		{path: `$["a/b"]['m~n']`, expected: "/a~1b/m~0n"},
+		{path: `$['it\'s']`, expected: "/it's"},
+		{path: "$.foo-bar", expected: "/foo-bar"},
+		{path: "@.foo", err: true},
+		{path: "$..foo", err: true},
+		{path: "$.*", err: true},
+		{path: "$[0,1]", err: true},
+		{path: "$[1:2]", err: true},
+		{path: "$[-1]", err: true},
+		{path: "$[?(@.foo)]", err: true},
+		{path: "$[(@.length-1)]", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			result, err := JSONPathToPointer(test.path)
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error, got: %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestPointer_roundTrip(t *testing.T) {
+	root := Must(Unmarshal(pointerTestData))
+	nodes, err := root.JSONPath("$..*")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	for _, node := range nodes {
+		path, err := PointerToJSONPath(node.PointerPath())
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		found, err := root.JSONPath(path)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if len(found) != 1 || found[0] != node {
+			t.Errorf("Wrong result for %s: %v", path, found)
+		}
+	}
+}
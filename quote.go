@@ -1,6 +1,9 @@
 package ajson
 
-import "unicode/utf8"
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
 // This file was copied from encoding/json library.
 // fixme: https://github.com/spyzhov/ajson/issues/13
@@ -221,11 +224,15 @@ var htmlSafeSet = [utf8.RuneSelf]bool{
 }
 
 func quoteString(s string, escapeHTML bool) []byte {
-	result := make([]byte, 0, len(s))
+	return MarshalOptions{DisableHTMLEscape: !escapeHTML}.appendString(make([]byte, 0, len(s)), s)
+}
+
+// appendString appends the escaped string without quotes, according to the escaping options
+func (o MarshalOptions) appendString(result []byte, s string) []byte {
 	start := 0
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
-			if htmlSafeSet[b] || (!escapeHTML && safeSet[b]) {
+			if htmlSafeSet[b] || (o.DisableHTMLEscape && safeSet[b]) {
 				i++
 				continue
 			}
@@ -273,16 +280,19 @@ func quoteString(s string, escapeHTML bool) []byte {
 		// They are both technically valid characters in JSON strings,
 		// but don't work in JSONP, which has to be evaluated as JavaScript,
 		// and can lead to security holes there. It is valid JSON to
-		// escape them, so we do so unconditionally.
+		// escape them, so we do so unless it was disabled.
 		// See http://timelessrepo.com/json-isnt-a-javascript-subset for discussion.
-		if c == '\u2028' || c == '\u2029' {
+		if (c == '\u2028' || c == '\u2029') && !o.DisableLineTerminatorEscape || o.ASCIIOnly {
 			if start < i {
 				result = append(result, s[start:i]...)
 			}
-			result = append(result, []byte(`\u202`)...)
-			result = append(result, hex[c&0xF])
-			// e.WriteString(`\u202`)
-			// e.WriteByte(hex[c&0xF])
+			if c > 0xFFFF {
+				r1, r2 := utf16.EncodeRune(c)
+				result = appendRune(result, r1)
+				result = appendRune(result, r2)
+			} else {
+				result = appendRune(result, c)
+			}
 			i += size
 			start = i
 			continue
@@ -294,3 +304,18 @@ func quoteString(s string, escapeHTML bool) []byte {
 	}
 	return result
 }
+
+// appendRune appends the rune from the Basic Multilingual Plane as \uXXXX
+func appendRune(result []byte, c rune) []byte {
+	return append(result, '\\', 'u', hex[c>>12&0xF], hex[c>>8&0xF], hex[c>>4&0xF], hex[c&0xF])
+}
+
+// isASCII checks if the data contains only 7-bit characters
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+package ajson
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// refKey is the key of JSON Reference objects: {"$ref": "common.json#/defs/x"}
+const refKey = "$ref"
+
+// refMaxNodes is the default maximum total count of nodes, copied by references
+const refMaxNodes = 1000000
+
+// RefOptions are options for ResolveRefsWithOptions
+type RefOptions struct {
+	// Loader returns the root node of the document by its URI, LoadRefFile by default
+	Loader func(uri string) (*Node, error)
+	// BaseURI is the URI of the root document: the file path or the absolute URI, relative references of the root
+	// document are resolved against it. Without it they are passed to the loader as is.
+	BaseURI string
+	// KeepCycles keeps references, which create cycles, instead of the error. References of the root document are
+	// left as they are, with other members. References of loaded documents are replaced with {"$ref": "uri#pointer"},
+	// where the URI is resolved against the URI of their document and omitted for the root document, so they can
+	// be resolved lazily with the loader.
+	KeepCycles bool
+	// MaxNodes is the maximum total count of nodes, copied by references, 1000000 by default. Every reference copies
+	// its target, so nested references to shared targets can expand exponentially.
+	MaxNodes int
+}
+
+// refResolver keeps loaded documents and references in process of resolving, targets are taken from documents
+// before resolving, so the result doesn't depend on the order of references
+type refResolver struct {
+	options   RefOptions
+	base      string
+	documents map[string]*Node
+	stack     []refLocation
+	copied    int
+}
+
+// refLocation is the location of the reference in process of resolving
+type refLocation struct {
+	document string
+	pointer  string
+}
+
+// ResolveRefs replaces JSON References {"$ref": "uri#/json/pointer"} with clones of their targets, other members of
+// references are ignored. References without URI point to the current document, other documents are loaded with the
+// loader, relative URIs are resolved against the URI of the document, which contains the reference: RefOptions.BaseURI
+// for the root document.
+// Relative file paths are loaded with LoadRefFile, if the loader is nil. Cyclic references cause the error, as well as
+// references, which copy more than 1000000 nodes in total.
+// Example:
+//
+//	common := Must(Unmarshal([]byte(`{"y": [2]}`)))
+//	root := Must(Unmarshal([]byte(`{"a": {"$ref": "#/defs/x"}, "b": {"$ref": "common.json#/y"}, "defs": {"x": 1}}`)))
+//	err := ResolveRefs(root, func(uri string) (*Node, error) { return common, nil })
+//	// root.String() == `{"a":1,"b":[2],"defs":{"x": 1}}`, "defs" is not changed and keeps its source formatting
+//
+func ResolveRefs(root *Node, loader func(uri string) (*Node, error)) error {
+	return ResolveRefsWithOptions(root, RefOptions{Loader: loader})
+}
+
+// ResolveRefsWithOptions replaces JSON References with clones of their targets with the given options.
+func ResolveRefsWithOptions(root *Node, options RefOptions) error {
+	if root == nil {
+		return errorUnparsed()
+	}
+	if options.Loader == nil {
+		options.Loader = LoadRefFile
+	}
+	if options.MaxNodes <= 0 {
+		options.MaxNodes = refMaxNodes
+	}
+	r := &refResolver{
+		options:   options,
+		base:      options.BaseURI,
+		documents: map[string]*Node{options.BaseURI: root.Clone()},
+	}
+	return r.resolve(root, r.base, "")
+}
+
+// LoadRefFile returns the root node of the JSON file by its path: relative paths are relative to the working
+// directory, "file://" URIs are allowed.
+func LoadRefFile(uri string) (*Node, error) {
+	if strings.HasPrefix(uri, "file://") {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+		uri = parsed.Path
+	}
+	data, err := ioutil.ReadFile(uri)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
+
+// resolve replaces references in the node, document is the URI of the document of the node and location is
+// the JSON Pointer of the node in it
+func (r *refResolver) resolve(node *Node, document string, location string) error {
+	if node == nil {
+		return errorUnparsed()
+	}
+	if ref, ok := refOf(node); ok {
+		return r.reference(node, document, location, ref)
+	}
+	for i, child := range node.children {
+		token := strconv.Itoa(i)
+		if node.IsObject() {
+			token = escapePointer(child.Key())
+		}
+		if err := r.resolve(child, document, location+"/"+token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reference replaces the reference node with the clone of its target and resolves it
+func (r *refResolver) reference(node *Node, document string, location string, ref string) error {
+	uri, fragment := ref, ""
+	if index := strings.IndexByte(ref, '#'); index >= 0 {
+		uri, fragment = ref[:index], ref[index+1:]
+	}
+	current := document
+	if uri != "" {
+		document = refURI(document, uri)
+	}
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil || pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return errorRequest("wrong reference %q", ref)
+	}
+	root, err := r.document(document)
+	if err != nil {
+		return err
+	}
+	target, err := root.Pointer(pointer)
+	if err != nil {
+		return errorRequest("reference %q not found", ref)
+	}
+	if r.cyclic(refLocation{document: current, pointer: location}, document, pointer) {
+		if !r.options.KeepCycles {
+			return errorRequest("cyclic reference %q", ref)
+		}
+		if current == r.base {
+			return nil
+		}
+		link := "#" + fragment
+		if document != r.base {
+			link = document + link
+		}
+		return node.SetNode(ObjectNode("", map[string]*Node{refKey: StringNode("", link)}))
+	}
+
+	target.Walk(func(*Node, int) WalkAction {
+		r.copied++
+		return WalkContinue
+	})
+	if r.copied > r.options.MaxNodes {
+		return errorRequest("references copy more than %d nodes", r.options.MaxNodes)
+	}
+	// the copy of the target is resolved in place, so resolved values are not copied again
+	if err = node.SetNode(target); err != nil {
+		return err
+	}
+	r.stack = append(r.stack, refLocation{document: current, pointer: location})
+	err = r.resolve(node, document, pointer)
+	r.stack = r.stack[:len(r.stack)-1]
+	return err
+}
+
+// cyclic checks if the target contains the reference or any reference in process of resolving
+func (r *refResolver) cyclic(current refLocation, document string, pointer string) bool {
+	for _, location := range append(r.stack, current) {
+		if location.document == document &&
+			(location.pointer == pointer || strings.HasPrefix(location.pointer, pointer+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// document returns the root node of the document by its URI, documents are loaded once
+func (r *refResolver) document(uri string) (*Node, error) {
+	if root, ok := r.documents[uri]; ok {
+		return root, nil
+	}
+	root, err := r.options.Loader(uri)
+	if err != nil {
+		return nil, errorRequest("loading %q: %s", uri, err)
+	}
+	if root == nil {
+		return nil, errorUnparsed()
+	}
+	r.documents[uri] = root
+	return root, nil
+}
+
+// refOf returns the value of the reference, if the node is the JSON Reference object
+func refOf(node *Node) (string, bool) {
+	if !node.IsObject() {
+		return "", false
+	}
+	member, ok := node.members[refKey]
+	if !ok || !member.IsString() {
+		return "", false
+	}
+	value, err := member.GetString()
+	return value, err == nil
+}
+
+// refURI resolves the URI against the URI of the document: absolute URIs and paths are kept as is, relative paths
+// are relative to the directory of the document
+func refURI(document string, uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.IsAbs() || path.IsAbs(uri) || document == "" {
+		return uri
+	}
+	if base, err := url.Parse(document); err == nil && base.IsAbs() {
+		return base.ResolveReference(parsed).String()
+	}
+	return path.Join(path.Dir(document), uri)
+}
@@ -0,0 +1,231 @@
+package ajson
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// refLoader returns the loader of documents from the map
+func refLoader(documents map[string]string) func(uri string) (*Node, error) {
+	return func(uri string) (*Node, error) {
+		data, ok := documents[uri]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return Unmarshal([]byte(data))
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	documents := map[string]string{
+		"common.json":     `{"defs": {"y": {"type": "string"}, "z": {"$ref": "#/defs/y"}, "w": {"$ref": "sub/other.json"}}}`,
+		"sub/other.json":  `{"a": {"$ref": "../common.json#/defs/y"}}`,
+		"http://x/a.json": `{"b": {"$ref": "c.json#/d"}}`,
+		"http://x/c.json": `{"d": 1}`,
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "none", input: `{"a": [1, {"b": 2}]}`, expected: `{"a":[1,{"b":2}]}`},
+		{name: "local", input: `{"a": {"$ref": "#/defs/x"}, "defs": {"x": [1]}}`, expected: `{"a":[1],"defs":{"x":[1]}}`},
+		{name: "shared", input: `{"a": {"$ref": "#/c"}, "b": {"$ref": "#/c"}, "c": {"d": [{"$ref": "#/e"}]}, "e": 1}`, expected: `{"a":{"d":[1]},"b":{"d":[1]},"c":{"d":[1]},"e":1}`},
+		{name: "chain", input: `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": 1}`, expected: `{"a":1,"b":1,"c":1}`},
+		{name: "escaped", input: `{"a": {"$ref": "#/b~1c/d%20e"}, "b/c": {"d e": true}}`, expected: `{"a":true,"b/c":{"d e":true}}`},
+		{name: "siblings", input: `{"a": {"$ref": "#/b", "c": 1}, "b": 2}`, expected: `{"a":2,"b":2}`},
+		{name: "not reference", input: `{"properties": {"$ref": {"type": "string"}}}`, expected: `{"properties":{"$ref":{"type":"string"}}}`},
+		{name: "document", input: `{"a": {"$ref": "common.json#/defs/z"}}`, expected: `{"a":{"type":"string"}}`},
+		{name: "relative", input: `{"a": {"$ref": "common.json#/defs/w"}}`, expected: `{"a":{"a":{"type":"string"}}}`},
+		{name: "whole", input: `{"$ref": "common.json"}`, expected: `{"defs":{"y":{"type":"string"},"z":{"type":"string"},"w":{"a":{"type":"string"}}}}`},
+		{name: "url", input: `[{"$ref": "http://x/a.json#/b"}]`, expected: `[1]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			if err := ResolveRefs(root, refLoader(documents)); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if ok, _ := root.Eq(Must(Unmarshal([]byte(test.expected)))); !ok {
+				t.Errorf("Wrong result: %s, expected: %s", root, test.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRefs_errors(t *testing.T) {
+	documents := map[string]string{
+		"cycle.json": `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/a"}}`,
+		"a.json":     `{"x": {"next": {"$ref": "b.json#/y"}}}`,
+		"b.json":     `{"y": {"next": {"$ref": "a.json#/x"}}}`,
+	}
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "not found", input: `{"a": {"$ref": "#/b"}}`, err: `wrong request: reference "#/b" not found`},
+		{name: "anchor", input: `{"a": {"$ref": "#b"}}`, err: `wrong request: wrong reference "#b"`},
+		{name: "escape", input: `{"a": {"$ref": "#/%zz"}}`, err: `wrong request: wrong reference "#/%zz"`},
+		{name: "document", input: `{"a": {"$ref": "none.json"}}`, err: `wrong request: loading "none.json": not found`},
+		{name: "self", input: `{"a": {"b": {"$ref": "#/a"}}}`, err: `wrong request: cyclic reference "#/a"`},
+		{name: "root", input: `{"a": [{"$ref": "#"}]}`, err: `wrong request: cyclic reference "#"`},
+		{name: "mutual", input: `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/a"}}`, err: `wrong request: cyclic reference "#/a"`},
+		{name: "remote", input: `{"$ref": "cycle.json#/a"}`, err: `wrong request: cyclic reference "#/a"`},
+		{name: "documents", input: `{"$ref": "a.json#/x"}`, err: `wrong request: cyclic reference "a.json#/x"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ResolveRefs(Must(Unmarshal([]byte(test.input))), refLoader(documents))
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+	if err := ResolveRefs(nil, nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestResolveRefsWithOptions_maxNodes(t *testing.T) {
+	// every level doubles the count of copied nodes
+	data := `{"d0": [1]`
+	for i := 1; i <= 30; i++ {
+		data += fmt.Sprintf(`, "d%d": [{"$ref": "#/d%d"}, {"$ref": "#/d%[2]d"}]`, i, i-1)
+	}
+	data += "}"
+	tests := []struct {
+		name     string
+		input    string
+		maxNodes int
+		err      string
+	}{
+		{name: "default", input: data, err: "wrong request: references copy more than 1000000 nodes"},
+		{name: "option", input: `{"a": {"$ref": "#/b"}, "b": [1, 2], "c": {"$ref": "#/b"}}`, maxNodes: 5, err: "wrong request: references copy more than 5 nodes"},
+		{name: "enough", input: `{"a": {"$ref": "#/b"}, "b": [1, 2], "c": {"$ref": "#/b"}}`, maxNodes: 6},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ResolveRefsWithOptions(Must(Unmarshal([]byte(test.input))), RefOptions{MaxNodes: test.maxNodes})
+			if test.err == "" && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("Wrong error: %v, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestResolveRefsWithOptions_keepCycles(t *testing.T) {
+	documents := map[string]string{
+		"list.json":       `{"node": {"value": 1, "next": {"$ref": "#/node"}}}`,
+		"a.json":          `{"x": {"next": {"$ref": "b.json#/y"}}}`,
+		"b.json":          `{"y": {"next": {"$ref": "a.json#/x"}}}`,
+		"dir/other.json":  `{"b": {"back": {"$ref": "root.json#/a"}}}`,
+		"dir/common.json": `{"c": {"next": {"$ref": "#/c", "d": 1}}}`,
+	}
+	tests := []struct {
+		name     string
+		input    string
+		base     string
+		expected string
+	}{
+		{
+			name:     "self",
+			input:    `{"node": {"value": 1, "next": {"$ref": "#/node"}}}`,
+			expected: `{"node":{"value":1,"next":{"$ref":"#/node"}}}`,
+		},
+		{
+			name:     "reference",
+			input:    `{"head": {"$ref": "#/node"}, "node": {"value": 1, "next": {"$ref": "#/node"}}}`,
+			expected: `{"head":{"value":1,"next":{"$ref":"#/node"}},"node":{"value":1,"next":{"$ref":"#/node"}}}`,
+		},
+		{
+			name:     "mutual",
+			input:    `{"a": {"b": {"$ref": "#/c"}}, "c": {"d": {"$ref": "#/a"}}}`,
+			expected: `{"a":{"b":{"d":{"$ref":"#/a"}}},"c":{"d":{"b":{"$ref":"#/c"}}}}`,
+		},
+		{
+			name:     "document",
+			input:    `{"list": {"$ref": "list.json#/node"}}`,
+			expected: `{"list":{"value":1,"next":{"$ref":"list.json#/node"}}}`,
+		},
+		{
+			name:     "documents",
+			input:    `{"$ref": "a.json#/x"}`,
+			expected: `{"next":{"next":{"$ref":"a.json#/x"}}}`,
+		},
+		{
+			name:     "siblings",
+			input:    `{"a": {"$ref": "#/a", "b": 1}}`,
+			expected: `{"a":{"$ref":"#/a","b":1}}`,
+		},
+		{
+			name:     "base",
+			input:    `{"a": {"$ref": "other.json#/b"}, "c": {"$ref": "common.json#/c"}}`,
+			base:     "dir/root.json",
+			expected: `{"a":{"back":{"$ref":"#/a"}},"c":{"next":{"$ref":"dir/common.json#/c"}}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			options := RefOptions{Loader: refLoader(documents), BaseURI: test.base, KeepCycles: true}
+			if err := ResolveRefsWithOptions(root, options); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if ok, _ := root.Eq(Must(Unmarshal([]byte(test.expected)))); !ok {
+				t.Errorf("Wrong result: %s, expected: %s", root, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoadRefFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ajson")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	files := map[string]string{
+		"common.json":    `{"defs": {"id": {"$ref": "types/id.json"}}}`,
+		"types/id.json":  `{"type": "integer"}`,
+		"types/bad.json": `{`,
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err == nil {
+			err = ioutil.WriteFile(name, []byte(data), 0644)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	root := Must(Unmarshal([]byte(`{"a": {"$ref": "` + filepath.ToSlash(filepath.Join(dir, "common.json")) + `#/defs/id"}}`)))
+	if err = ResolveRefs(root, nil); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if ok, _ := root.Eq(Must(Unmarshal([]byte(`{"a": {"type": "integer"}}`)))); !ok {
+		t.Errorf("Wrong result: %s", root)
+	}
+	root = Must(Unmarshal([]byte(`{"a": {"$ref": "types/id.json"}}`)))
+	if err = ResolveRefsWithOptions(root, RefOptions{BaseURI: filepath.ToSlash(filepath.Join(dir, "root.json"))}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if ok, _ := root.Eq(Must(Unmarshal([]byte(`{"a": {"type": "integer"}}`)))); !ok {
+		t.Errorf("Wrong result: %s", root)
+	}
+	if _, err = LoadRefFile("file://" + filepath.ToSlash(filepath.Join(dir, "types/id.json"))); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if _, err = LoadRefFile(filepath.Join(dir, "types/bad.json")); err == nil {
+		t.Errorf("Expected error for wrong JSON")
+	}
+	if _, err = LoadRefFile(filepath.Join(dir, "none.json")); err == nil {
+		t.Errorf("Expected error for missing file")
+	}
+}
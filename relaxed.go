@@ -0,0 +1,271 @@
+package ajson
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	. "github.com/spyzhov/ajson/internal"
+)
+
+var (
+	_infinity = []byte("Infinity")
+	_nan      = []byte("NaN")
+)
+
+// relaxedState returns the action code for the JSON5 extensions, or the state from StateTransitionTable otherwise
+func (b *buffer) relaxedState(current *Node) States {
+	c := b.data[b.index]
+	switch b.state {
+	case GO, VA, AR:
+		switch {
+		case c == quotes || c == quote:
+			return rs
+		case c == plus || c == minus || c == dot || (c >= '0' && c <= '9') || c == _infinity[0] || c == _nan[0]:
+			return rn
+		case c == bracketR && b.state == VA && current != nil && current.IsArray():
+			// trailing comma
+			current.mark()
+			return bc
+		}
+	case OB, KE:
+		switch {
+		case c == quotes || c == quote || isIdentifier(c, true):
+			return rk
+		case c == bracesR && b.state == KE:
+			// trailing comma
+			current.mark()
+			return cc
+		}
+	}
+	return b.getState()
+}
+
+// skipRelaxed skips whitespaces and comments, returns true if there was something except JSON whitespaces
+func (b *buffer) skipRelaxed() (extra bool, err error) {
+	for b.index < b.length {
+		c := b.data[b.index]
+		switch {
+		case c == skipS || c == skipR || c == skipN || c == skipT:
+			b.index++
+		case c == '\v' || c == '\f':
+			extra = true
+			b.index++
+		case c == '/' && b.index+1 < b.length && b.data[b.index+1] == '/':
+			extra = true
+			for b.index < b.length && b.data[b.index] != skipN && b.data[b.index] != skipR {
+				b.index++
+			}
+		case c == '/' && b.index+1 < b.length && b.data[b.index+1] == '*':
+			extra = true
+			end := bytes.Index(b.data[b.index+2:], []byte("*/"))
+			if end == -1 {
+				b.index = b.length
+				return extra, b.errorEOF()
+			}
+			b.index += end + 4
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(b.data[b.index:])
+			if r != '\uFEFF' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+				return extra, nil
+			}
+			extra = true
+			b.index += size
+		default:
+			return extra, nil
+		}
+	}
+	return extra, io.EOF
+}
+
+// relaxedString moves index from the opening quote to the closing one, both single and double quotes are allowed
+func (b *buffer) relaxedString() error {
+	border := b.data[b.index]
+	for b.index++; b.index < b.length; b.index++ {
+		switch b.data[b.index] {
+		case border:
+			return nil
+		case backslash:
+			b.index++
+			if b.index+1 < b.length && b.data[b.index] == skipR && b.data[b.index+1] == skipN {
+				b.index++
+			}
+		case skipN, skipR:
+			return b.errorSymbol()
+		}
+	}
+	return b.errorEOF()
+}
+
+// relaxedNumeric moves index to the last symbol of the JSON5 numeric
+func (b *buffer) relaxedNumeric() error {
+	if c := b.data[b.index]; c == plus || c == minus {
+		b.index++
+	}
+	if b.index >= b.length {
+		return b.errorEOF()
+	}
+	switch c := b.data[b.index]; {
+	case c == _infinity[0]:
+		return b.word(_infinity)
+	case c == _nan[0]:
+		return b.word(_nan)
+	case c == '0' && b.index+1 < b.length && (b.data[b.index+1] == 'x' || b.data[b.index+1] == 'X'):
+		b.index += 2
+		start := b.index
+		for b.index < b.length && isHex(b.data[b.index]) {
+			b.index++
+		}
+		if b.index == start {
+			return b.errorSymbol()
+		}
+		b.index--
+		return nil
+	}
+	start := b.index
+	digits := b.digits()
+	if digits > 1 && b.data[start] == '0' {
+		return errorAt(start, '0')
+	}
+	if b.index < b.length && b.data[b.index] == dot {
+		b.index++
+		digits += b.digits()
+	}
+	if digits == 0 {
+		return b.errorSymbol()
+	}
+	if b.index < b.length && (b.data[b.index] == 'e' || b.data[b.index] == 'E') {
+		b.index++
+		if b.index < b.length && (b.data[b.index] == plus || b.data[b.index] == minus) {
+			b.index++
+		}
+		if b.digits() == 0 {
+			return b.errorSymbol()
+		}
+	}
+	b.index--
+	return nil
+}
+
+// digits moves index to the first non-digit symbol, returns the count of skipped digits
+func (b *buffer) digits() (count int) {
+	for ; b.index < b.length && b.data[b.index] >= '0' && b.data[b.index] <= '9'; b.index++ {
+		count++
+	}
+	return
+}
+
+// identifier moves index to the last symbol of the ECMAScript 5.1 identifier name
+func (b *buffer) identifier() error {
+	start := b.index
+	for b.index < b.length {
+		c := b.data[b.index]
+		if c < utf8.RuneSelf {
+			if !isIdentifier(c, b.index == start) {
+				break
+			}
+			b.index++
+			continue
+		}
+		r, size := utf8.DecodeRune(b.data[b.index:])
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.Nl, r) &&
+			(b.index == start || !unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) && r != '\u200C' && r != '\u200D') {
+			break
+		}
+		b.index += size
+	}
+	if b.index == start {
+		return b.errorSymbol()
+	}
+	b.index--
+	return nil
+}
+
+// relaxedKey returns the value of the key: quoted with single or double quotes or an identifier name;
+// strict is true if the key is a valid JSON string.
+func relaxedKey(b *buffer, options Options) (key *string, strict bool, err error) {
+	start := b.index
+	if c := b.data[start]; c != quotes && c != quote {
+		if err = b.identifier(); err != nil {
+			return nil, false, err
+		}
+		if err = options.stringLen(start-1, b.index+1); err != nil {
+			return nil, false, err
+		}
+		value := string(b.data[start : b.index+1])
+		return &value, false, nil
+	}
+	if err = b.relaxedString(); err != nil {
+		return nil, false, err
+	}
+	if err = options.stringLen(start, b.index); err != nil {
+		return nil, false, err
+	}
+	source := b.data[start : b.index+1]
+	value, ok := unquoteRelaxed(source)
+	if !ok {
+		return nil, false, errorAt(start, source[0])
+	}
+	return &value, newBuffer(source).string(quotes, true) == nil, nil
+}
+
+// relaxedValue stores the value of the String or Numeric node and marks it as changed, if its source is not a valid JSON
+func relaxedValue(node *Node) error {
+	source := node.Source()
+	switch node._type {
+	case String:
+		if newBuffer(source).string(quotes, true) == nil {
+			return nil
+		}
+		value, ok := unquoteRelaxed(source)
+		if !ok {
+			return errorAt(node.borders[0], source[0])
+		}
+		node.value.Store(value)
+	case Numeric:
+		if newBuffer(source).numeric(false) == nil {
+			return nil
+		}
+		value, err := relaxedNumber(source)
+		if err != nil {
+			return errorAt(node.borders[0], source[0])
+		}
+		node.value.Store(value)
+	}
+	node.mark()
+	return nil
+}
+
+// relaxedNumber converts JSON5 numeric into the float64
+func relaxedNumber(source []byte) (float64, error) {
+	sign := 1.0
+	if source[0] == plus || source[0] == minus {
+		if source[0] == minus {
+			sign = -1
+		}
+		source = source[1:]
+	}
+	switch {
+	case bytes.Equal(source, _infinity):
+		return math.Inf(int(sign)), nil
+	case bytes.Equal(source, _nan):
+		return math.NaN(), nil
+	case len(source) > 1 && (source[1] == 'x' || source[1] == 'X'):
+		value, err := strconv.ParseUint(string(source[2:]), 16, 64)
+		return sign * float64(value), err
+	}
+	value, err := strconv.ParseFloat(string(source), 64)
+	return sign * value, err
+}
+
+func isIdentifier(c byte, first bool) bool {
+	return c == dollar || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9') ||
+		(first && c >= utf8.RuneSelf)
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,160 @@
+package ajson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnmarshalWithOptions_Relaxed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "strict", input: `{"a": [1, "b", true, null]}`, expected: `{"a": [1, "b", true, null]}`},
+		{name: "line comment", input: "[1, // comment\n2]", expected: `[1,2]`},
+		{name: "block comment", input: `/* a */ {/* b */"a" /* c */: /* d */ 1 /* e */} /* f */`, expected: `{"a":1}`},
+		{name: "comment at the end", input: "1 // one", expected: `1`},
+		{name: "comment in value", input: `{"a": [1 /* comment */], "b": [2]}`, expected: `{"a":[1],"b":[2]}`},
+		{name: "trailing comma: array", input: `[1, 2,]`, expected: `[1,2]`},
+		{name: "trailing comma: object", input: `{"a": 1, "b": [{},],}`, expected: `{"a":1,"b":[{}]}`},
+		{name: "single quotes", input: `['a"b', 'it\'s']`, expected: `["a\"b","it's"]`},
+		{name: "single quotes: key", input: `{'a': 1}`, expected: `{"a":1}`},
+		{name: "unquoted keys", input: `{a: 1, $b_2: 2, ключ: 3}`, expected: `{"a":1,"$b_2":2,"ключ":3}`},
+		{name: "escapes", input: `['\x41\v\0B\
+C']`, expected: `["A\u000b\u0000BC"]`},
+		{name: "hexadecimal", input: `[0x1F, -0XfF, +0x0]`, expected: `[31,-255,0]`},
+		{name: "decimal point", input: `[.5, 5., -.5e1, +1]`, expected: `[0.5,5,-5,1]`},
+		{name: "whitespaces", input: "\uFEFF[1,\v\f\u00A0\u2028 2]", expected: `[1,2]`},
+		{name: "nested strict", input: `{a: {"b": [1, 2]}}`, expected: `{"a":{"b": [1, 2]}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.input), Options{Relaxed: true})
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result: %s, expected: %s", result, test.expected)
+			}
+			if _, err = Unmarshal(result); err != nil {
+				t.Errorf("Result is not a valid JSON: %s", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_Relaxed_special(t *testing.T) {
+	root, err := UnmarshalWithOptions([]byte(`[Infinity, -Infinity, +Infinity, NaN]`), Options{Relaxed: true})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	values := root.MustArray()
+	if !math.IsInf(values[0].MustNumeric(), 1) || !math.IsInf(values[1].MustNumeric(), -1) ||
+		!math.IsInf(values[2].MustNumeric(), 1) || !math.IsNaN(values[3].MustNumeric()) {
+		t.Errorf("Wrong result: %v", values)
+	}
+}
+
+func TestUnmarshalWithOptions_Relaxed_invalid(t *testing.T) {
+	tests := []string{
+		`[1,,]`,
+		`[,]`,
+		`{,}`,
+		`{"a":1,,}`,
+		`{"a",}`,
+		`{"a":}`,
+		`{"a":1,]`,
+		`[1,}`,
+		`[1 /* comment`,
+		`/* comment */`,
+		`[01]`,
+		`[0x]`,
+		`[1e]`,
+		`[.]`,
+		`[+]`,
+		`['abc]`,
+		"['a\nb']",
+		`['\1']`,
+		`['\x4']`,
+		`[Inf]`,
+		`[nan]`,
+		`{1a: 1}`,
+		`{a-b: 1}`,
+		`{a b: 1}`,
+		`[1 2]`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if root, err := UnmarshalWithOptions([]byte(test), Options{Relaxed: true}); err == nil {
+				t.Errorf("Expected error, got: %s", root)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_strictByDefault(t *testing.T) {
+	tests := []string{
+		`[1, // comment
+2]`,
+		`[1,]`,
+		`{"a":1,}`,
+		`['a']`,
+		`{a: 1}`,
+		`[0x10]`,
+		`[.5]`,
+		`[Infinity]`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if root, err := Unmarshal([]byte(test)); err == nil {
+				t.Errorf("Expected error, got: %s", root)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptions_Relaxed_options(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		_type   ErrorType
+	}{
+		{name: "MaxStringLen", input: `['abcd']`, options: Options{Relaxed: true, MaxStringLen: 3}, _type: MaxStringLenExceeded},
+		{name: "MaxStringLen: identifier", input: `{abcd: 1}`, options: Options{Relaxed: true, MaxStringLen: 3}, _type: MaxStringLenExceeded},
+		{name: "MaxKeys", input: `{a: 1, 'b': 2}`, options: Options{Relaxed: true, MaxKeys: 1}, _type: MaxKeysExceeded},
+		{name: "DuplicateKeys", input: `{a: 1, 'a': 2}`, options: Options{Relaxed: true, DuplicateKeys: DuplicateKeysReject}, _type: DuplicateKey},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalWithOptions([]byte(test.input), test.options)
+			if err == nil {
+				t.Errorf("Expected error, got: %s", root)
+			} else if e, ok := err.(Error); !ok || e.Type != test._type {
+				t.Errorf("Wrong error: %#v", err)
+			}
+		})
+	}
+}
+
+func TestNode_relaxed_values(t *testing.T) {
+	root := Must(UnmarshalWithOptions([]byte(`{a: 'x', "b": "y", c: 0x10, "d": 10}`), Options{Relaxed: true}))
+	if value := root.MustKey("a").MustString(); value != "x" {
+		t.Errorf("Wrong value: %s", value)
+	}
+	if value := root.MustKey("c").MustNumeric(); value != 16 {
+		t.Errorf("Wrong value: %f", value)
+	}
+	if root.MustKey("b").dirty || root.MustKey("d").dirty {
+		t.Errorf("Strict values should not be changed")
+	}
+	if !root.MustKey("a").dirty || !root.MustKey("c").dirty || !root.dirty {
+		t.Errorf("Relaxed values should be changed")
+	}
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// formats are the checks of the "format" keyword values
+var formats = map[string]func(value string) bool{
+	"date-time":     isDateTime,
+	"date":          isDate,
+	"time":          isTime,
+	"email":         isEmail,
+	"hostname":      isHostname,
+	"ipv4":          isIPv4,
+	"ipv6":          isIPv6,
+	"uri":           isURI,
+	"uri-reference": isURIReference,
+	"uuid":          isUUID,
+	"regex":         isRegex,
+	"json-pointer":  isJSONPointer,
+}
+
+var (
+	_email = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	_label = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	_time  = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`)
+	_uuid  = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+)
+
+// isDateTime checks the date-time of RFC 3339, e.g. 2020-01-02T03:04:05.6Z
+func isDateTime(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(value))
+	return err == nil
+}
+
+// isDate checks the full-date of RFC 3339, e.g. 2020-01-02
+func isDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+// isTime checks the full-time of RFC 3339, e.g. 03:04:05+01:00
+func isTime(value string) bool {
+	return _time.MatchString(value) && isDateTime("2000-01-01T"+value)
+}
+
+func isEmail(value string) bool {
+	return _email.MatchString(value)
+}
+
+func isHostname(value string) bool {
+	value = strings.TrimSuffix(value, ".")
+	if value == "" || len(value) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if !_label.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIPv4(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+}
+
+func isIPv6(value string) bool {
+	return net.ParseIP(value) != nil && strings.Contains(value, ":")
+}
+
+func isURI(value string) bool {
+	result, err := url.Parse(value)
+	return err == nil && result.IsAbs()
+}
+
+func isURIReference(value string) bool {
+	_, err := url.Parse(value)
+	return err == nil
+}
+
+func isUUID(value string) bool {
+	return _uuid.MatchString(value)
+}
+
+func isRegex(value string) bool {
+	_, err := regexp.Compile(value)
+	return err == nil
+}
+
+func isJSONPointer(value string) bool {
+	if value != "" && value[0] != '/' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] == '~' && (i+1 == len(value) || value[i+1] != '0' && value[i+1] != '1') {
+			return false
+		}
+	}
+	return true
+}
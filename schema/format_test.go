@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{
+			format:  "date-time",
+			valid:   []string{"2020-01-02T03:04:05Z", "2020-01-02t03:04:05.123+01:00"},
+			invalid: []string{"2020-01-02 03:04:05Z", "2020-13-02T03:04:05Z", "2020-01-02T03:04:05"},
+		},
+		{format: "date", valid: []string{"2020-02-29"}, invalid: []string{"2021-02-29", "2020-1-2", "20200102"}},
+		{format: "time", valid: []string{"03:04:05Z", "23:59:59.5+01:00"}, invalid: []string{"24:00:00Z", "03:04:05", "3:04:05Z"}},
+		{format: "email", valid: []string{"user@example.com"}, invalid: []string{"user", "user@", "a b@example.com", "user@example"}},
+		{
+			format:  "hostname",
+			valid:   []string{"example.com", "a-b.example.com.", "localhost"},
+			invalid: []string{"", "-a.com", "a-.com", "a..com", "a_b.com"},
+		},
+		{format: "ipv4", valid: []string{"127.0.0.1"}, invalid: []string{"256.0.0.1", "1.2.3", "::1", "::ffff:1.2.3.4"}},
+		{format: "ipv6", valid: []string{"::1", "2001:db8::ff00:42:8329", "::ffff:1.2.3.4"}, invalid: []string{"127.0.0.1", "1::2::3"}},
+		{format: "uri", valid: []string{"https://example.com/a?b#c", "urn:isbn:0451450523"}, invalid: []string{"/a/b", "example.com", "http://[::1"}},
+		{format: "uri-reference", valid: []string{"/a/b", "#c", "https://example.com"}, invalid: []string{"http://[::1"}},
+		{format: "uuid", valid: []string{"123e4567-e89b-12d3-a456-426614174000"}, invalid: []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"}},
+		{format: "regex", valid: []string{"^a+$"}, invalid: []string{"("}},
+		{format: "json-pointer", valid: []string{"", "/", "/a~0b/c~1d/0"}, invalid: []string{"a", "/a~", "/a~2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			check := formats[test.format]
+			for _, value := range test.valid {
+				if !check(value) {
+					t.Errorf("Expected to be valid: %s", value)
+				}
+			}
+			for _, value := range test.invalid {
+				if check(value) {
+					t.Errorf("Expected to be invalid: %s", value)
+				}
+			}
+		})
+	}
+}
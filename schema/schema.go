@@ -0,0 +1,482 @@
+// Package schema implements validation of ajson nodes by JSON Schema (draft 2020-12).
+//
+// Supported keywords are:
+//   - core: $ref (within the document: "#", JSON Pointer fragments and $anchor), $defs;
+//   - applicators: allOf, anyOf, oneOf, not, if, then, else, dependentSchemas, prefixItems, items, contains,
+//     properties, patternProperties, additionalProperties, propertyNames;
+//   - validation: type, enum, const, multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum, maxLength,
+//     minLength, pattern, maxItems, minItems, uniqueItems, maxContains, minContains, maxProperties, minProperties,
+//     required, dependentRequired;
+//   - format assertions: date-time, date, time, email, hostname, ipv4, ipv6, uri, uri-reference, uuid, regex,
+//     json-pointer. Unknown formats are ignored.
+//
+// Example:
+//
+//	schema, err := Compile(ajson.Must(ajson.Unmarshal([]byte(`{"type": "object", "required": ["name"]}`))))
+//	if err != nil {
+//		panic(err)
+//	}
+//	err = schema.Validate(ajson.Must(ajson.Unmarshal([]byte(`{"title": "example"}`))))
+//	// err.Error() == "$: required property 'name' is missing (/required)"
+//
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spyzhov/ajson"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	root *rule
+}
+
+// rule is a compiled schema object or a boolean schema
+type rule struct {
+	always *bool
+
+	ref *rule
+
+	types    []string
+	enum     []*ajson.Node
+	constant *ajson.Node
+
+	multipleOf       *float64
+	maximum          *float64
+	exclusiveMaximum *float64
+	minimum          *float64
+	exclusiveMinimum *float64
+
+	maxLength *int
+	minLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	prefixItems []*rule
+	items       *rule
+	contains    *rule
+	maxContains *int
+	minContains *int
+	maxItems    *int
+	minItems    *int
+	uniqueItems bool
+
+	properties           map[string]*rule
+	patternProperties    []patternRule
+	additionalProperties *rule
+	propertyNames        *rule
+	required             []string
+	maxProperties        *int
+	minProperties        *int
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*rule
+
+	allOf    []*rule
+	anyOf    []*rule
+	oneOf    []*rule
+	not      *rule
+	ifRule   *rule
+	thenRule *rule
+	elseRule *rule
+}
+
+// patternRule is a compiled member of patternProperties
+type patternRule struct {
+	source  string
+	pattern *regexp.Regexp
+	rule    *rule
+}
+
+// compiler keeps compiled rules of the document, so $ref can point to any of them, including the recursive ones
+type compiler struct {
+	root      *ajson.Node
+	rules     map[*ajson.Node]*rule
+	locations map[*rule]string
+	anchors   map[string]*ajson.Node
+}
+
+var types = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"string":  true,
+	"integer": true,
+}
+
+// Compile returns the compiled schema from the node, or an error if the schema is not valid: references, which make
+// cycles of rules applied to the same value, e.g. {"$defs": {"a": {"$ref": "#"}}, "$ref": "#/$defs/a"}, are errors too
+func Compile(node *ajson.Node) (*Schema, error) {
+	if node == nil {
+		return nil, fmt.Errorf("schema: node is nil")
+	}
+	c := &compiler{
+		root:      node,
+		rules:     make(map[*ajson.Node]*rule),
+		locations: make(map[*rule]string),
+		anchors:   make(map[string]*ajson.Node),
+	}
+	c.collectAnchors(node)
+	root, err := c.compile(node, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = c.cycles(root, make(map[*rule]bool)); err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile returns the compiled schema from the node, panics on error
+func MustCompile(node *ajson.Node) *Schema {
+	schema, err := Compile(node)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func (c *compiler) collectAnchors(node *ajson.Node) {
+	if node.IsObject() {
+		if anchor, err := node.GetKey("$anchor"); err == nil && anchor.IsString() {
+			c.anchors[anchor.MustString()] = node
+		}
+	}
+	for _, child := range node.Inheritors() {
+		c.collectAnchors(child)
+	}
+}
+
+func (c *compiler) compile(node *ajson.Node, location string) (*rule, error) {
+	if result, ok := c.rules[node]; ok {
+		return result, nil
+	}
+	result := new(rule)
+	c.rules[node] = result
+	c.locations[result] = location
+
+	if node.IsBool() {
+		value := node.MustBool()
+		result.always = &value
+		return result, nil
+	}
+	if !node.IsObject() {
+		return nil, errorSchema(location, "schema should be an object or a boolean")
+	}
+
+	var err error
+	for _, keyword := range node.Keys() {
+		value := node.MustKey(keyword)
+		current := location + "/" + escape(keyword)
+		switch keyword {
+		case "$ref":
+			err = c.reference(result, value, current)
+		case "type":
+			result.types, err = c.types(value, current)
+		case "enum":
+			if !value.IsArray() {
+				return nil, errorSchema(current, "should be an array")
+			}
+			result.enum = value.MustArray()
+		case "const":
+			result.constant = value
+		case "multipleOf":
+			if result.multipleOf, err = c.number(value, current); err == nil && *result.multipleOf <= 0 {
+				err = errorSchema(current, "should be greater than 0")
+			}
+		case "maximum":
+			result.maximum, err = c.number(value, current)
+		case "exclusiveMaximum":
+			result.exclusiveMaximum, err = c.number(value, current)
+		case "minimum":
+			result.minimum, err = c.number(value, current)
+		case "exclusiveMinimum":
+			result.exclusiveMinimum, err = c.number(value, current)
+		case "maxLength":
+			result.maxLength, err = c.count(value, current)
+		case "minLength":
+			result.minLength, err = c.count(value, current)
+		case "pattern":
+			result.pattern, err = c.pattern(value, current)
+		case "format":
+			if !value.IsString() {
+				return nil, errorSchema(current, "should be a string")
+			}
+			result.format = value.MustString()
+		case "prefixItems":
+			result.prefixItems, err = c.schemas(value, current)
+		case "items":
+			result.items, err = c.compile(value, current)
+		case "contains":
+			result.contains, err = c.compile(value, current)
+		case "maxContains":
+			result.maxContains, err = c.count(value, current)
+		case "minContains":
+			result.minContains, err = c.count(value, current)
+		case "maxItems":
+			result.maxItems, err = c.count(value, current)
+		case "minItems":
+			result.minItems, err = c.count(value, current)
+		case "uniqueItems":
+			if !value.IsBool() {
+				return nil, errorSchema(current, "should be a boolean")
+			}
+			result.uniqueItems = value.MustBool()
+		case "properties":
+			result.properties, err = c.schemaMap(value, current)
+		case "patternProperties":
+			result.patternProperties, err = c.patternProperties(value, current)
+		case "additionalProperties":
+			result.additionalProperties, err = c.compile(value, current)
+		case "propertyNames":
+			result.propertyNames, err = c.compile(value, current)
+		case "required":
+			result.required, err = c.strings(value, current)
+		case "maxProperties":
+			result.maxProperties, err = c.count(value, current)
+		case "minProperties":
+			result.minProperties, err = c.count(value, current)
+		case "dependentRequired":
+			result.dependentRequired, err = c.dependentRequired(value, current)
+		case "dependentSchemas":
+			result.dependentSchemas, err = c.schemaMap(value, current)
+		case "allOf":
+			result.allOf, err = c.schemas(value, current)
+		case "anyOf":
+			result.anyOf, err = c.schemas(value, current)
+		case "oneOf":
+			result.oneOf, err = c.schemas(value, current)
+		case "not":
+			result.not, err = c.compile(value, current)
+		case "if":
+			result.ifRule, err = c.compile(value, current)
+		case "then":
+			result.thenRule, err = c.compile(value, current)
+		case "else":
+			result.elseRule, err = c.compile(value, current)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// cycles checks that references don't create cycles of rules, which are applied to the same value: such cycles never
+// descend into the value, so the validation would never end. Active rules are true in the path, checked are false.
+func (c *compiler) cycles(current *rule, path map[*rule]bool) error {
+	if active, ok := path[current]; ok {
+		if active {
+			return errorSchema(c.locations[current], "reference cycle is applied to the same value")
+		}
+		return nil
+	}
+	path[current] = true
+	for _, next := range current.applicators() {
+		if err := c.cycles(next, path); err != nil {
+			return err
+		}
+	}
+	path[current] = false
+	return nil
+}
+
+// reference resolves the $ref within the document: "#", "#/json/pointer" or "#anchor"
+func (c *compiler) reference(result *rule, value *ajson.Node, location string) error {
+	if !value.IsString() {
+		return errorSchema(location, "should be a string")
+	}
+	ref := value.MustString()
+	if !strings.HasPrefix(ref, "#") {
+		return errorSchema(location, fmt.Sprintf("unsupported reference '%s', only references within the document are allowed", ref))
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return errorSchema(location, fmt.Sprintf("wrong reference '%s'", ref))
+	}
+	var target *ajson.Node
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		target, err = c.root.Pointer(fragment)
+	} else if anchor, ok := c.anchors[fragment]; ok {
+		target = anchor
+	} else {
+		err = fmt.Errorf("anchor not found")
+	}
+	if err != nil {
+		return errorSchema(location, fmt.Sprintf("reference '%s' not found", ref))
+	}
+	result.ref, err = c.compile(target, location)
+	return err
+}
+
+func (c *compiler) types(value *ajson.Node, location string) ([]string, error) {
+	var result []string
+	if value.IsString() {
+		result = []string{value.MustString()}
+	} else {
+		var err error
+		if result, err = c.strings(value, location); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range result {
+		if !types[name] {
+			return nil, errorSchema(location, fmt.Sprintf("unknown type '%s'", name))
+		}
+	}
+	return result, nil
+}
+
+func (c *compiler) number(value *ajson.Node, location string) (*float64, error) {
+	if !value.IsNumeric() {
+		return nil, errorSchema(location, "should be a number")
+	}
+	result := value.MustNumeric()
+	return &result, nil
+}
+
+// count returns the non-negative integer value
+func (c *compiler) count(value *ajson.Node, location string) (*int, error) {
+	number, err := c.number(value, location)
+	if err != nil || *number < 0 || *number != float64(int(*number)) {
+		return nil, errorSchema(location, "should be a non-negative integer")
+	}
+	result := int(*number)
+	return &result, nil
+}
+
+func (c *compiler) pattern(value *ajson.Node, location string) (*regexp.Regexp, error) {
+	if !value.IsString() {
+		return nil, errorSchema(location, "should be a string")
+	}
+	result, err := regexp.Compile(value.MustString())
+	if err != nil {
+		return nil, errorSchema(location, fmt.Sprintf("wrong pattern: %s", err))
+	}
+	return result, nil
+}
+
+func (c *compiler) strings(value *ajson.Node, location string) ([]string, error) {
+	if !value.IsArray() {
+		return nil, errorSchema(location, "should be an array of strings")
+	}
+	result := make([]string, 0, value.Size())
+	for _, child := range value.MustArray() {
+		if !child.IsString() {
+			return nil, errorSchema(location, "should be an array of strings")
+		}
+		result = append(result, child.MustString())
+	}
+	return result, nil
+}
+
+// schemas returns the non-empty list of compiled schemas
+func (c *compiler) schemas(value *ajson.Node, location string) ([]*rule, error) {
+	if !value.IsArray() || value.Size() == 0 {
+		return nil, errorSchema(location, "should be a non-empty array")
+	}
+	result := make([]*rule, 0, value.Size())
+	for i, child := range value.MustArray() {
+		current, err := c.compile(child, fmt.Sprintf("%s/%d", location, i))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, current)
+	}
+	return result, nil
+}
+
+func (c *compiler) schemaMap(value *ajson.Node, location string) (map[string]*rule, error) {
+	if !value.IsObject() {
+		return nil, errorSchema(location, "should be an object")
+	}
+	result := make(map[string]*rule, value.Size())
+	for _, key := range value.Keys() {
+		current, err := c.compile(value.MustKey(key), location+"/"+escape(key))
+		if err != nil {
+			return nil, err
+		}
+		result[key] = current
+	}
+	return result, nil
+}
+
+func (c *compiler) patternProperties(value *ajson.Node, location string) ([]patternRule, error) {
+	if !value.IsObject() {
+		return nil, errorSchema(location, "should be an object")
+	}
+	result := make([]patternRule, 0, value.Size())
+	for _, key := range value.Keys() {
+		current := location + "/" + escape(key)
+		pattern, err := regexp.Compile(key)
+		if err != nil {
+			return nil, errorSchema(current, fmt.Sprintf("wrong pattern: %s", err))
+		}
+		compiled, err := c.compile(value.MustKey(key), current)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, patternRule{source: key, pattern: pattern, rule: compiled})
+	}
+	return result, nil
+}
+
+func (c *compiler) dependentRequired(value *ajson.Node, location string) (map[string][]string, error) {
+	if !value.IsObject() {
+		return nil, errorSchema(location, "should be an object")
+	}
+	result := make(map[string][]string, value.Size())
+	for _, key := range value.Keys() {
+		names, err := c.strings(value.MustKey(key), location+"/"+escape(key))
+		if err != nil {
+			return nil, err
+		}
+		result[key] = names
+	}
+	return result, nil
+}
+
+// applicators returns rules, which are applied to the same value as the rule
+func (r *rule) applicators() []*rule {
+	result := make([]*rule, 0)
+	for _, current := range []*rule{r.ref, r.not, r.ifRule, r.thenRule, r.elseRule} {
+		if current != nil {
+			result = append(result, current)
+		}
+	}
+	result = append(result, r.allOf...)
+	result = append(result, r.anyOf...)
+	result = append(result, r.oneOf...)
+	for _, key := range sortedRuleKeys(r.dependentSchemas) {
+		result = append(result, r.dependentSchemas[key])
+	}
+	return result
+}
+
+// sortedRuleKeys returns keys of rules in order
+func sortedRuleKeys(value map[string]*rule) []string {
+	result := make([]string, 0, len(value))
+	for key := range value {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func errorSchema(location, message string) error {
+	if location == "" {
+		location = "/"
+	}
+	return fmt.Errorf("schema: wrong schema at '%s': %s", location, message)
+}
+
+// escape returns the JSON Pointer token of the key
+func escape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
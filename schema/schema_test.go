@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func compile(t *testing.T, schema string) *Schema {
+	result, err := Compile(ajson.Must(ajson.Unmarshal([]byte(schema))))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return result
+}
+
+func TestCompile_errors(t *testing.T) {
+	tests := []string{
+		`1`,
+		`"string"`,
+		`null`,
+		`[]`,
+		`{"type": "unknown"}`,
+		`{"type": 1}`,
+		`{"type": ["string", 1]}`,
+		`{"enum": 1}`,
+		`{"multipleOf": 0}`,
+		`{"maximum": "1"}`,
+		`{"maxLength": -1}`,
+		`{"minLength": 1.5}`,
+		`{"pattern": "("}`,
+		`{"pattern": 1}`,
+		`{"format": 1}`,
+		`{"items": 1}`,
+		`{"prefixItems": []}`,
+		`{"uniqueItems": 1}`,
+		`{"properties": []}`,
+		`{"properties": {"a": 1}}`,
+		`{"patternProperties": {"(": {}}}`,
+		`{"required": "a"}`,
+		`{"required": [1]}`,
+		`{"dependentRequired": {"a": "b"}}`,
+		`{"allOf": {}}`,
+		`{"anyOf": []}`,
+		`{"not": "a"}`,
+		`{"$ref": 1}`,
+		`{"$ref": "other.json#/a"}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "#missing"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}}, "$ref": "#/$defs/a"}`,
+		`{"$ref": "#"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"$defs": {"a": {"anyOf": [{"type": "string"}, {"$ref": "#"}]}}, "allOf": [{"$ref": "#/$defs/a"}]}`,
+		`{"$defs": {"a": {"dependentSchemas": {"x": {"$ref": "#/$defs/a"}}}}, "not": {"$ref": "#/$defs/a"}}`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if _, err := Compile(ajson.Must(ajson.Unmarshal([]byte(test)))); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+	if _, err := Compile(nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestCompile_cycles(t *testing.T) {
+	_, err := Compile(ajson.Must(ajson.Unmarshal([]byte(`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`))))
+	if err == nil {
+		t.Errorf("Expected error")
+	} else if err.Error() != "schema: wrong schema at '/$ref': reference cycle is applied to the same value" {
+		t.Errorf("Wrong error: %s", err)
+	}
+	// cycles, which descend into the value, are allowed
+	schema, err := Compile(ajson.Must(ajson.Unmarshal([]byte(`{"anyOf": [{"type": "number"}, {"items": {"$ref": "#"}}]}`))))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if !schema.IsValid(ajson.Must(ajson.Unmarshal([]byte(`[1, [2, [3]]]`)))) {
+		t.Errorf("Expected valid value")
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic")
+		}
+	}()
+	MustCompile(ajson.NumericNode("", 1))
+}
+
+func TestCompile_ref(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		valid    []string
+		invalid  []string
+		location string
+	}{
+		{
+			name:     "defs",
+			schema:   `{"$defs": {"positive": {"type": "integer", "minimum": 1}}, "items": {"$ref": "#/$defs/positive"}}`,
+			valid:    []string{`[1, 2]`, `[]`},
+			invalid:  []string{`[0]`, `[1.5]`},
+			location: "/items/$ref/",
+		},
+		{
+			name:     "escaped pointer",
+			schema:   `{"$defs": {"a/b": {"const": 1}, "c%d": {"const": 2}}, "properties": {"x": {"$ref": "#/$defs/a~1b"}, "y": {"$ref": "#/$defs/c%25d"}}}`,
+			valid:    []string{`{"x": 1, "y": 2}`},
+			invalid:  []string{`{"x": 2}`, `{"y": 1}`},
+			location: "/properties/",
+		},
+		{
+			name:     "anchor",
+			schema:   `{"$defs": {"name": {"$anchor": "name", "type": "string"}}, "properties": {"name": {"$ref": "#name"}}}`,
+			valid:    []string{`{"name": "a"}`},
+			invalid:  []string{`{"name": 1}`},
+			location: "/properties/name/$ref/type",
+		},
+		{
+			name:     "recursive",
+			schema:   `{"type": "object", "properties": {"value": {"type": "number"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+			valid:    []string{`{"value": 1, "children": [{"value": 2, "children": []}, {}]}`},
+			invalid:  []string{`{"children": [{"children": [{"value": "1"}]}]}`, `{"children": [1]}`},
+			location: "/properties/children/items/$ref/",
+		},
+		{
+			name:     "ref with siblings",
+			schema:   `{"$defs": {"a": {"type": "number"}}, "$ref": "#/$defs/a", "maximum": 10}`,
+			valid:    []string{`1`},
+			invalid:  []string{`"1"`, `11`},
+			location: "/",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema := compile(t, test.schema)
+			for _, value := range test.valid {
+				if err := schema.Validate(ajson.Must(ajson.Unmarshal([]byte(value)))); err != nil {
+					t.Errorf("Unexpected error for %s: %s", value, err)
+				}
+			}
+			for _, value := range test.invalid {
+				err := schema.Validate(ajson.Must(ajson.Unmarshal([]byte(value))))
+				if err == nil {
+					t.Errorf("Expected error for %s", value)
+				} else if errors := err.(Errors); errors[0].KeywordLocation[:len(test.location)] != test.location {
+					t.Errorf("Wrong location for %s: %s", value, errors[0].KeywordLocation)
+				}
+			}
+		})
+	}
+}
+
+func Test_escape(t *testing.T) {
+	if value := escape("a/b~c"); value != "a~1b~0c" {
+		t.Errorf("Wrong value: %s", value)
+	}
+}
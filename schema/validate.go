@@ -0,0 +1,339 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spyzhov/ajson"
+)
+
+// Error is a single validation error
+type Error struct {
+	// InstancePath is the path of the invalid node, as returned by Node.Path()
+	InstancePath string
+	// KeywordLocation is the JSON Pointer of the failed keyword in the schema, through all passed $ref
+	KeywordLocation string
+	// Message is the description of the error
+	Message string
+}
+
+// Errors is the list of all validation errors of the node
+type Errors []Error
+
+// Error is implementation of error interface
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.InstancePath, e.Message, e.KeywordLocation)
+}
+
+// Error is implementation of error interface
+func (e Errors) Error() string {
+	result := make([]string, 0, len(e))
+	for _, err := range e {
+		result = append(result, err.Error())
+	}
+	return strings.Join(result, "\n")
+}
+
+// Validate checks the node with the schema. Returns nil if the node is valid, or Errors with all validation errors.
+func (s *Schema) Validate(node *ajson.Node) error {
+	if node == nil {
+		return fmt.Errorf("schema: node is nil")
+	}
+	errors := make(Errors, 0)
+	s.root.validate(node, "", &errors)
+	if len(errors) != 0 {
+		return errors
+	}
+	return nil
+}
+
+// IsValid checks if the node is valid by the schema
+func (s *Schema) IsValid(node *ajson.Node) bool {
+	return node != nil && s.root.valid(node, "")
+}
+
+func (r *rule) valid(node *ajson.Node, location string) bool {
+	errors := make(Errors, 0)
+	r.validate(node, location, &errors)
+	return len(errors) == 0
+}
+
+func (r *rule) validate(node *ajson.Node, location string, errors *Errors) {
+	report := func(keyword string, format string, args ...interface{}) {
+		*errors = append(*errors, Error{
+			InstancePath:    node.Path(),
+			KeywordLocation: location + "/" + keyword,
+			Message:         fmt.Sprintf(format, args...),
+		})
+	}
+
+	if r.always != nil {
+		if !*r.always {
+			*errors = append(*errors, Error{
+				InstancePath:    node.Path(),
+				KeywordLocation: location,
+				Message:         "value is not allowed",
+			})
+		}
+		return
+	}
+
+	if r.ref != nil {
+		r.ref.validate(node, location+"/$ref", errors)
+	}
+	if len(r.types) != 0 && !r.matchType(node) {
+		report("type", "wrong type: %s, expected: %s", typeOf(node), strings.Join(r.types, ", "))
+	}
+	if r.enum != nil && !contains(r.enum, node) {
+		report("enum", "value is not one of the allowed values")
+	}
+	if r.constant != nil && !equal(r.constant, node) {
+		report("const", "value is not equal to the constant")
+	}
+
+	switch node.Type() {
+	case ajson.Numeric:
+		r.validateNumber(node.MustNumeric(), report)
+	case ajson.String:
+		r.validateString(node.MustString(), report)
+	case ajson.Array:
+		r.validateArray(node, location, errors, report)
+	case ajson.Object:
+		r.validateObject(node, location, errors, report)
+	}
+
+	for i, current := range r.allOf {
+		current.validate(node, location+"/allOf/"+strconv.Itoa(i), errors)
+	}
+	if r.anyOf != nil {
+		matched := false
+		for i, current := range r.anyOf {
+			if current.valid(node, location+"/anyOf/"+strconv.Itoa(i)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("anyOf", "value doesn't match any of the schemas")
+		}
+	}
+	if r.oneOf != nil {
+		count := 0
+		for i, current := range r.oneOf {
+			if current.valid(node, location+"/oneOf/"+strconv.Itoa(i)) {
+				count++
+			}
+		}
+		if count != 1 {
+			report("oneOf", "value should match exactly one schema, but matches %d", count)
+		}
+	}
+	if r.not != nil && r.not.valid(node, location+"/not") {
+		report("not", "value should not match the schema")
+	}
+	if r.ifRule != nil {
+		if r.ifRule.valid(node, location+"/if") {
+			if r.thenRule != nil {
+				r.thenRule.validate(node, location+"/then", errors)
+			}
+		} else if r.elseRule != nil {
+			r.elseRule.validate(node, location+"/else", errors)
+		}
+	}
+}
+
+func (r *rule) validateNumber(value float64, report func(keyword string, format string, args ...interface{})) {
+	if r.multipleOf != nil {
+		quotient := value / *r.multipleOf
+		if math.IsInf(quotient, 0) || math.Abs(quotient-math.Round(quotient)) > 1e-9*math.Max(1, math.Abs(quotient)) {
+			report("multipleOf", "value %v is not a multiple of %v", value, *r.multipleOf)
+		}
+	}
+	if r.maximum != nil && value > *r.maximum {
+		report("maximum", "value %v is greater than %v", value, *r.maximum)
+	}
+	if r.exclusiveMaximum != nil && value >= *r.exclusiveMaximum {
+		report("exclusiveMaximum", "value %v is greater than or equal to %v", value, *r.exclusiveMaximum)
+	}
+	if r.minimum != nil && value < *r.minimum {
+		report("minimum", "value %v is less than %v", value, *r.minimum)
+	}
+	if r.exclusiveMinimum != nil && value <= *r.exclusiveMinimum {
+		report("exclusiveMinimum", "value %v is less than or equal to %v", value, *r.exclusiveMinimum)
+	}
+}
+
+func (r *rule) validateString(value string, report func(keyword string, format string, args ...interface{})) {
+	length := utf8.RuneCountInString(value)
+	if r.maxLength != nil && length > *r.maxLength {
+		report("maxLength", "length %d is greater than %d", length, *r.maxLength)
+	}
+	if r.minLength != nil && length < *r.minLength {
+		report("minLength", "length %d is less than %d", length, *r.minLength)
+	}
+	if r.pattern != nil && !r.pattern.MatchString(value) {
+		report("pattern", "value doesn't match the pattern '%s'", r.pattern)
+	}
+	if r.format != "" {
+		if check, ok := formats[r.format]; ok && !check(value) {
+			report("format", "value is not a valid %s", r.format)
+		}
+	}
+}
+
+func (r *rule) validateArray(node *ajson.Node, location string, errors *Errors, report func(keyword string, format string, args ...interface{})) {
+	items := node.MustArray()
+	for i, current := range r.prefixItems {
+		if i < len(items) {
+			current.validate(items[i], location+"/prefixItems/"+strconv.Itoa(i), errors)
+		}
+	}
+	if r.items != nil {
+		for i := len(r.prefixItems); i < len(items); i++ {
+			r.items.validate(items[i], location+"/items", errors)
+		}
+	}
+	if r.contains != nil {
+		count := 0
+		for _, item := range items {
+			if r.contains.valid(item, location+"/contains") {
+				count++
+			}
+		}
+		minimum := 1
+		if r.minContains != nil {
+			minimum = *r.minContains
+		}
+		if count < minimum {
+			if r.minContains != nil {
+				report("minContains", "array contains %d matching items, expected at least %d", count, minimum)
+			} else {
+				report("contains", "array doesn't contain a matching item")
+			}
+		}
+		if r.maxContains != nil && count > *r.maxContains {
+			report("maxContains", "array contains %d matching items, expected at most %d", count, *r.maxContains)
+		}
+	}
+	if r.maxItems != nil && len(items) > *r.maxItems {
+		report("maxItems", "array has %d items, expected at most %d", len(items), *r.maxItems)
+	}
+	if r.minItems != nil && len(items) < *r.minItems {
+		report("minItems", "array has %d items, expected at least %d", len(items), *r.minItems)
+	}
+	if r.uniqueItems {
+		for i := 1; i < len(items); i++ {
+			if contains(items[:i], items[i]) {
+				report("uniqueItems", "array items are not unique")
+				break
+			}
+		}
+	}
+}
+
+func (r *rule) validateObject(node *ajson.Node, location string, errors *Errors, report func(keyword string, format string, args ...interface{})) {
+	keys := node.Keys()
+	for _, key := range keys {
+		child := node.MustKey(key)
+		evaluated := false
+		if current, ok := r.properties[key]; ok {
+			evaluated = true
+			current.validate(child, location+"/properties/"+escape(key), errors)
+		}
+		for _, pattern := range r.patternProperties {
+			if pattern.pattern.MatchString(key) {
+				evaluated = true
+				pattern.rule.validate(child, location+"/patternProperties/"+escape(pattern.source), errors)
+			}
+		}
+		if !evaluated && r.additionalProperties != nil {
+			r.additionalProperties.validate(child, location+"/additionalProperties", errors)
+		}
+		if r.propertyNames != nil && !r.propertyNames.valid(ajson.StringNode("", key), location+"/propertyNames") {
+			report("propertyNames", "property name '%s' is not valid", key)
+		}
+	}
+	for _, key := range r.required {
+		if !node.HasKey(key) {
+			report("required", "required property '%s' is missing", key)
+		}
+	}
+	if r.maxProperties != nil && len(keys) > *r.maxProperties {
+		report("maxProperties", "object has %d properties, expected at most %d", len(keys), *r.maxProperties)
+	}
+	if r.minProperties != nil && len(keys) < *r.minProperties {
+		report("minProperties", "object has %d properties, expected at least %d", len(keys), *r.minProperties)
+	}
+	for _, key := range sortedKeys(r.dependentRequired) {
+		if !node.HasKey(key) {
+			continue
+		}
+		for _, name := range r.dependentRequired[key] {
+			if !node.HasKey(name) {
+				report("dependentRequired/"+escape(key), "property '%s' is required by '%s'", name, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		if current, ok := r.dependentSchemas[key]; ok {
+			current.validate(node, location+"/dependentSchemas/"+escape(key), errors)
+		}
+	}
+}
+
+func (r *rule) matchType(node *ajson.Node) bool {
+	current := typeOf(node)
+	for _, name := range r.types {
+		if name == current || name == "number" && current == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of the node, "integer" is used for numbers without a fractional part
+func typeOf(node *ajson.Node) string {
+	switch node.Type() {
+	case ajson.Null:
+		return "null"
+	case ajson.Bool:
+		return "boolean"
+	case ajson.Numeric:
+		if value := node.MustNumeric(); value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case ajson.String:
+		return "string"
+	case ajson.Array:
+		return "array"
+	}
+	return "object"
+}
+
+func equal(left, right *ajson.Node) bool {
+	result, err := left.Eq(right)
+	return err == nil && result
+}
+
+func contains(list []*ajson.Node, node *ajson.Node) bool {
+	for _, current := range list {
+		if equal(current, node) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(value map[string][]string) []string {
+	result := make([]string, 0, len(value))
+	for key := range value {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
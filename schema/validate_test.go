@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spyzhov/ajson"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		valid   []string
+		invalid []string
+	}{
+		{name: "true", schema: `true`, valid: []string{`1`, `{}`}},
+		{name: "false", schema: `false`, invalid: []string{`1`, `{}`}},
+		{name: "empty", schema: `{}`, valid: []string{`1`, `"a"`, `null`}},
+		{name: "type", schema: `{"type": "string"}`, valid: []string{`"a"`}, invalid: []string{`1`, `null`}},
+		{name: "type: list", schema: `{"type": ["null", "boolean"]}`, valid: []string{`null`, `true`}, invalid: []string{`0`}},
+		{name: "type: integer", schema: `{"type": "integer"}`, valid: []string{`1`, `1.0`, `-2e3`}, invalid: []string{`1.5`, `"1"`}},
+		{name: "type: number", schema: `{"type": "number"}`, valid: []string{`1`, `1.5`}, invalid: []string{`"1"`}},
+		{name: "type: object", schema: `{"type": "object"}`, valid: []string{`{}`}, invalid: []string{`[]`}},
+		{name: "type: array", schema: `{"type": "array"}`, valid: []string{`[]`}, invalid: []string{`{}`}},
+		{name: "enum", schema: `{"enum": [1, "a", [null], {"b": true}]}`, valid: []string{`1.0`, `"a"`, `[null]`, `{"b": true}`}, invalid: []string{`2`, `[]`, `{"b": false}`}},
+		{name: "const", schema: `{"const": {"a": [1, 2]}}`, valid: []string{`{"a": [1, 2.0]}`}, invalid: []string{`{"a": [2, 1]}`}},
+		{name: "multipleOf", schema: `{"multipleOf": 0.01}`, valid: []string{`0.07`, `19.99`, `0`}, invalid: []string{`0.075`}},
+		{name: "maximum", schema: `{"maximum": 3}`, valid: []string{`3`, `"10"`}, invalid: []string{`3.1`}},
+		{name: "exclusiveMaximum", schema: `{"exclusiveMaximum": 3}`, valid: []string{`2.9`}, invalid: []string{`3`}},
+		{name: "minimum", schema: `{"minimum": 3}`, valid: []string{`3`}, invalid: []string{`2.9`}},
+		{name: "exclusiveMinimum", schema: `{"exclusiveMinimum": 3}`, valid: []string{`3.1`}, invalid: []string{`3`}},
+		{name: "maxLength", schema: `{"maxLength": 2}`, valid: []string{`"ab"`, `"ёж"`, `1`}, invalid: []string{`"abc"`}},
+		{name: "minLength", schema: `{"minLength": 2}`, valid: []string{`"ab"`}, invalid: []string{`"a"`, `""`}},
+		{name: "pattern", schema: `{"pattern": "^a+$"}`, valid: []string{`"aaa"`}, invalid: []string{`"ab"`}},
+		{name: "format", schema: `{"format": "ipv4"}`, valid: []string{`"127.0.0.1"`, `1`}, invalid: []string{`"::1"`}},
+		{name: "format: unknown", schema: `{"format": "unknown"}`, valid: []string{`"value"`}},
+		{name: "prefixItems", schema: `{"prefixItems": [{"type": "number"}, {"type": "string"}]}`, valid: []string{`[1, "a", null]`, `[1]`}, invalid: []string{`["a"]`, `[1, 2]`}},
+		{name: "items", schema: `{"prefixItems": [{"type": "number"}], "items": {"type": "string"}}`, valid: []string{`[1, "a", "b"]`}, invalid: []string{`[1, "a", 2]`}},
+		{name: "items: false", schema: `{"prefixItems": [true], "items": false}`, valid: []string{`[1]`}, invalid: []string{`[1, 2]`}},
+		{name: "contains", schema: `{"contains": {"type": "string"}}`, valid: []string{`[1, "a"]`}, invalid: []string{`[1]`, `[]`}},
+		{name: "minContains", schema: `{"contains": {"const": 1}, "minContains": 2}`, valid: []string{`[1, 1]`}, invalid: []string{`[1, 2]`}},
+		{name: "minContains: 0", schema: `{"contains": {"const": 1}, "minContains": 0}`, valid: []string{`[]`, `[2]`}},
+		{name: "maxContains", schema: `{"contains": {"const": 1}, "maxContains": 1}`, valid: []string{`[1, 2]`}, invalid: []string{`[1, 1]`}},
+		{name: "maxItems", schema: `{"maxItems": 1}`, valid: []string{`[1]`}, invalid: []string{`[1, 2]`}},
+		{name: "minItems", schema: `{"minItems": 1}`, valid: []string{`[1]`}, invalid: []string{`[]`}},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, valid: []string{`[1, "1", [1], {"a": 1}, {"a": 2}]`}, invalid: []string{`[1, 1.0]`, `[{"a": 1}, {"a": 1}]`}},
+		{name: "properties", schema: `{"properties": {"a": {"type": "number"}}}`, valid: []string{`{"a": 1, "b": "c"}`, `{}`}, invalid: []string{`{"a": "1"}`}},
+		{name: "patternProperties", schema: `{"patternProperties": {"^x-": {"type": "string"}}}`, valid: []string{`{"x-a": "b", "y": 1}`}, invalid: []string{`{"x-a": 1}`}},
+		{
+			name:    "additionalProperties",
+			schema:  `{"properties": {"a": true}, "patternProperties": {"^x-": true}, "additionalProperties": false}`,
+			valid:   []string{`{"a": 1, "x-b": 2}`},
+			invalid: []string{`{"a": 1, "b": 2}`},
+		},
+		{name: "propertyNames", schema: `{"propertyNames": {"maxLength": 2}}`, valid: []string{`{"ab": 1}`}, invalid: []string{`{"abc": 1}`}},
+		{name: "required", schema: `{"required": ["a", "b"]}`, valid: []string{`{"a": 1, "b": null}`, `[]`}, invalid: []string{`{"a": 1}`}},
+		{name: "maxProperties", schema: `{"maxProperties": 1}`, valid: []string{`{"a": 1}`}, invalid: []string{`{"a": 1, "b": 2}`}},
+		{name: "minProperties", schema: `{"minProperties": 1}`, valid: []string{`{"a": 1}`}, invalid: []string{`{}`}},
+		{name: "dependentRequired", schema: `{"dependentRequired": {"a": ["b"]}}`, valid: []string{`{"a": 1, "b": 2}`, `{"b": 2}`}, invalid: []string{`{"a": 1}`}},
+		{name: "dependentSchemas", schema: `{"dependentSchemas": {"a": {"required": ["b"]}}}`, valid: []string{`{"a": 1, "b": 2}`, `{}`}, invalid: []string{`{"a": 1}`}},
+		{name: "allOf", schema: `{"allOf": [{"type": "number"}, {"minimum": 2}]}`, valid: []string{`2`}, invalid: []string{`1`, `"2"`}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "number"}, {"type": "string"}]}`, valid: []string{`2`, `"2"`}, invalid: []string{`null`}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "number"}, {"minimum": 2}]}`, valid: []string{`1`, `"a"`}, invalid: []string{`2`}},
+		{name: "not", schema: `{"not": {"type": "null"}}`, valid: []string{`1`}, invalid: []string{`null`}},
+		{name: "if-then", schema: `{"if": {"type": "number"}, "then": {"minimum": 2}}`, valid: []string{`2`, `"1"`}, invalid: []string{`1`}},
+		{name: "if-else", schema: `{"if": {"type": "number"}, "else": {"type": "string"}}`, valid: []string{`1`, `"1"`}, invalid: []string{`null`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema := compile(t, test.schema)
+			for _, value := range test.valid {
+				node := ajson.Must(ajson.Unmarshal([]byte(value)))
+				if err := schema.Validate(node); err != nil {
+					t.Errorf("Unexpected error for %s: %s", value, err)
+				}
+				if !schema.IsValid(node) {
+					t.Errorf("Expected to be valid: %s", value)
+				}
+			}
+			for _, value := range test.invalid {
+				node := ajson.Must(ajson.Unmarshal([]byte(value)))
+				if err := schema.Validate(node); err == nil {
+					t.Errorf("Expected error for %s", value)
+				}
+				if schema.IsValid(node) {
+					t.Errorf("Expected to be invalid: %s", value)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_errors(t *testing.T) {
+	schema := compile(t, `{
+		"type": "object",
+		"required": ["name", "tags"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}},
+			"a/b": false
+		},
+		"$defs": {
+			"tag": {"type": "string", "format": "hostname"}
+		}
+	}`)
+	err := schema.Validate(ajson.Must(ajson.Unmarshal([]byte(`{"name": "", "tags": ["a.com", "-b", 1], "a/b": 1}`))))
+	expected := Errors{
+		{InstancePath: "$['name']", KeywordLocation: "/properties/name/minLength", Message: "length 0 is less than 1"},
+		{InstancePath: "$['tags'][1]", KeywordLocation: "/properties/tags/items/$ref/format", Message: "value is not a valid hostname"},
+		{InstancePath: "$['tags'][2]", KeywordLocation: "/properties/tags/items/$ref/type", Message: "wrong type: integer, expected: string"},
+		{InstancePath: "$['a/b']", KeywordLocation: "/properties/a~1b", Message: "value is not allowed"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Wrong errors:\n%v\nexpected:\n%v", err, expected)
+	}
+	if err.Error() != expected.Error() {
+		t.Errorf("Wrong message: %s", err)
+	}
+	if message := expected[0].Error(); message != "$['name']: length 0 is less than 1 (/properties/name/minLength)" {
+		t.Errorf("Wrong message: %s", message)
+	}
+	err = schema.Validate(ajson.Must(ajson.Unmarshal([]byte(`[]`))))
+	expected = Errors{{InstancePath: "$", KeywordLocation: "/type", Message: "wrong type: array, expected: object"}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Wrong errors:\n%v\nexpected:\n%v", err, expected)
+	}
+	if err = schema.Validate(nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestSchema_Validate_subtree(t *testing.T) {
+	schema := compile(t, `{"type": "number"}`)
+	root := ajson.Must(ajson.Unmarshal([]byte(`{"a": [1, "b"]}`)))
+	err := schema.Validate(root.MustKey("a").MustIndex(1))
+	if errors, ok := err.(Errors); !ok || errors[0].InstancePath != "$['a'][1]" {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func TestSchema_Validate_inferred(t *testing.T) {
+	samples := []*ajson.Node{
+		ajson.Must(ajson.Unmarshal([]byte(`{"id": 1, "status": "active", "tags": ["a"], "price": 1.5}`))),
+		ajson.Must(ajson.Unmarshal([]byte(`{"id": 2, "status": "active", "tags": [], "price": null}`))),
+		ajson.Must(ajson.Unmarshal([]byte(`{"id": 3, "status": "deleted", "tags": ["b", "c"], "note": "x"}`))),
+	}
+	schema, err := Compile(ajson.InferSchema(samples...))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	for _, sample := range samples {
+		if err = schema.Validate(sample); err != nil {
+			t.Errorf("Unexpected error for %s: %s", sample, err)
+		}
+	}
+	for _, value := range []string{`{"id": 1, "status": "unknown", "tags": []}`, `{"id": 4, "status": "active", "tags": []}`, `{"status": "active", "tags": []}`} {
+		if err = schema.Validate(ajson.Must(ajson.Unmarshal([]byte(value)))); err == nil {
+			t.Errorf("Expected error for %s", value)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package ajson
+
+import (
+	"io"
+	"strconv"
+
+	. "github.com/spyzhov/ajson/internal"
+)
+
+// TokenType is a kind of the token, returned by Tokenizer
+type TokenType int
+
+const (
+	// TokenBeginObject is an opening curly bracket of the object
+	TokenBeginObject TokenType = iota
+	// TokenEndObject is a closing curly bracket of the object
+	TokenEndObject
+	// TokenBeginArray is an opening square bracket of the array
+	TokenBeginArray
+	// TokenEndArray is a closing square bracket of the array
+	TokenEndArray
+	// TokenKey is a quoted key of the object's member
+	TokenKey
+	// TokenString is a quoted string value
+	TokenString
+	// TokenNumber is a numeric value
+	TokenNumber
+	// TokenBool is a true or false value
+	TokenBool
+	// TokenNull is a null value
+	TokenNull
+)
+
+// Token is a single lexical element of the JSON data
+type Token struct {
+	// Type of the token
+	Type TokenType
+	// Start is the offset of the first byte of the token
+	Start int
+	// End is the offset after the last byte of the token
+	End int
+	// Raw is the source of the token, e.g. quoted string or numeric as is
+	Raw []byte
+}
+
+// Tokenizer reads the JSON data token by token, without building a tree of nodes. Syntax of the data is validated
+// by the same state machine as Unmarshal does.
+// Example:
+//
+//	tokenizer := NewTokenizer([]byte(`{"a": [1, true]}`))
+//	for {
+//		token, err := tokenizer.Next()
+//		if err == io.EOF {
+//			break
+//		} else if err != nil {
+//			panic(err)
+//		}
+//		fmt.Printf("%s %s\n", token.Type, token.Raw)
+//	}
+//	// Output:
+//	// begin-object {
+//	// key "a"
+//	// begin-array [
+//	// number 1
+//	// bool true
+//	// end-array ]
+//	// end-object }
+//
+type Tokenizer struct {
+	buf     *buffer
+	stack   []NodeType
+	started bool
+	err     error
+}
+
+// NewTokenizer creates a Tokenizer for the data
+func NewTokenizer(data []byte) *Tokenizer {
+	return &Tokenizer{
+		buf:   newBuffer(data),
+		stack: make([]NodeType, 0),
+	}
+}
+
+// String is implementation of Stringer interface
+func (t TokenType) String() string {
+	switch t {
+	case TokenBeginObject:
+		return "begin-object"
+	case TokenEndObject:
+		return "end-object"
+	case TokenBeginArray:
+		return "begin-array"
+	case TokenEndArray:
+		return "end-array"
+	case TokenKey:
+		return "key"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBool:
+		return "bool"
+	case TokenNull:
+		return "null"
+	}
+	return "unknown"
+}
+
+// Value returns the value of the token: string for TokenKey and TokenString, float64 for TokenNumber,
+// bool for TokenBool and nil for others.
+func (t Token) Value() (interface{}, error) {
+	switch t.Type {
+	case TokenKey, TokenString:
+		value, ok := unquote(t.Raw, quotes)
+		if !ok {
+			return nil, errorAt(t.Start, t.Raw[0])
+		}
+		return value, nil
+	case TokenNumber:
+		value, err := strconv.ParseFloat(string(t.Raw), 64)
+		if err != nil {
+			return nil, errorAt(t.Start, t.Raw[0])
+		}
+		return value, nil
+	case TokenBool:
+		return t.Raw[0] == 't', nil
+	}
+	return nil, nil
+}
+
+// Depth returns the count of opened and not closed containers
+func (t *Tokenizer) Depth() int {
+	return len(t.stack)
+}
+
+// Next returns the next token of the data, io.EOF if data is over or an error if data is not a valid JSON.
+// After the first error, the same error will be returned.
+func (t *Tokenizer) Next() (token Token, err error) {
+	if t.err != nil {
+		return Token{}, t.err
+	}
+	token, err = t.next()
+	if err != nil {
+		t.err = err
+	}
+	return
+}
+
+func (t *Tokenizer) next() (Token, error) {
+	b := t.buf
+	for {
+		if t.started && b.step() != nil {
+			return t.end()
+		}
+		t.started = true
+		if _, err := b.first(); err != nil {
+			return t.end()
+		}
+
+		state := b.getState()
+		if state == __ {
+			return Token{}, b.errorSymbol()
+		}
+		start := b.index
+		if state >= GO {
+			var err error
+			switch b.state {
+			case ST:
+				key := b.last == OB || b.last == KE
+				if err = b.string(quotes, false); err != nil {
+					return Token{}, err
+				}
+				if key {
+					b.state = CO
+					return t.token(TokenKey, start), nil
+				}
+				b.state = OK
+				return t.token(TokenString, start), nil
+			case MI, ZE, IN:
+				err = b.numeric(false)
+				b.index--
+				b.state = OK
+				if err != nil {
+					return Token{}, err
+				}
+				return t.token(TokenNumber, start), nil
+			case T1, F1:
+				if b.state == T1 {
+					err = b.true()
+				} else {
+					err = b.false()
+				}
+				b.state = OK
+				if err != nil {
+					return Token{}, err
+				}
+				return t.token(TokenBool, start), nil
+			case N1:
+				err = b.null()
+				b.state = OK
+				if err != nil {
+					return Token{}, err
+				}
+				return t.token(TokenNull, start), nil
+			}
+			continue
+		}
+
+		switch state {
+		case co: /* { */
+			t.stack = append(t.stack, Object)
+			b.state = OB
+			return t.token(TokenBeginObject, start), nil
+		case bo: /* [ */
+			t.stack = append(t.stack, Array)
+			b.state = AR
+			return t.token(TokenBeginArray, start), nil
+		case ec, cc: /* } */
+			if t.top() != Object {
+				return Token{}, b.errorSymbol()
+			}
+			t.stack = t.stack[:len(t.stack)-1]
+			b.state = OK
+			return t.token(TokenEndObject, start), nil
+		case bc: /* ] */
+			if t.top() != Array {
+				return Token{}, b.errorSymbol()
+			}
+			t.stack = t.stack[:len(t.stack)-1]
+			b.state = OK
+			return t.token(TokenEndArray, start), nil
+		case cm: /* , */
+			switch t.top() {
+			case Object:
+				b.state = KE
+			case Array:
+				b.state = VA
+			default:
+				return Token{}, b.errorSymbol()
+			}
+		case cl: /* : */
+			b.state = VA
+		default: /* syntax error */
+			return Token{}, b.errorSymbol()
+		}
+	}
+}
+
+// token returns the token from the start to the current index of the buffer
+func (t *Tokenizer) token(_type TokenType, start int) Token {
+	return Token{
+		Type:  _type,
+		Start: start,
+		End:   t.buf.index + 1,
+		Raw:   t.buf.data[start : t.buf.index+1],
+	}
+}
+
+// top returns the type of the current container, or Null for the root level
+func (t *Tokenizer) top() NodeType {
+	if len(t.stack) == 0 {
+		return Null
+	}
+	return t.stack[len(t.stack)-1]
+}
+
+// end returns io.EOF if data was fully read, or an error otherwise
+func (t *Tokenizer) end() (Token, error) {
+	if t.buf.state != OK || len(t.stack) != 0 {
+		return Token{}, t.buf.errorEOF()
+	}
+	return Token{}, io.EOF
+}
@@ -0,0 +1,305 @@
+package ajson
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readTokens(data string) (result []string, err error) {
+	tokenizer := NewTokenizer([]byte(data))
+	for {
+		token, err := tokenizer.Next()
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return result, err
+		}
+		if string(token.Raw) != data[token.Start:token.End] {
+			return result, fmt.Errorf("wrong borders of %s: %d:%d", token.Raw, token.Start, token.End)
+		}
+		result = append(result, fmt.Sprintf("%s %s", token.Type, token.Raw))
+	}
+}
+
+func TestTokenizer_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "number", input: `123`, expected: []string{"number 123"}},
+		{name: "string", input: ` "abc" `, expected: []string{`string "abc"`}},
+		{name: "true", input: `true`, expected: []string{"bool true"}},
+		{name: "false", input: `false`, expected: []string{"bool false"}},
+		{name: "null", input: `null`, expected: []string{"null null"}},
+		{name: "empty object", input: `{}`, expected: []string{"begin-object {", "end-object }"}},
+		{name: "empty array", input: `[ ]`, expected: []string{"begin-array [", "end-array ]"}},
+		{
+			name:  "object",
+			input: `{"a": [1, -2.5e3, "b"], "c": {"d": null}, "e": false}`,
+			expected: []string{
+				"begin-object {",
+				`key "a"`,
+				"begin-array [",
+				"number 1",
+				"number -2.5e3",
+				`string "b"`,
+				"end-array ]",
+				`key "c"`,
+				"begin-object {",
+				`key "d"`,
+				"null null",
+				"end-object }",
+				`key "e"`,
+				"bool false",
+				"end-object }",
+			},
+		},
+		{
+			name:  "array of objects",
+			input: "[{\"a\":\"b\"},\n{}]",
+			expected: []string{
+				"begin-array [",
+				"begin-object {",
+				`key "a"`,
+				`string "b"`,
+				"end-object }",
+				"begin-object {",
+				"end-object }",
+				"end-array ]",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := readTokens(test.input)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", strings.Join(result, "\n"), strings.Join(test.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestTokenizer_Next_invalid(t *testing.T) {
+	tests := []string{
+		``,
+		` `,
+		`{`,
+		`[1,`,
+		`[1,]`,
+		`{"a"}`,
+		`{"a":}`,
+		`{"a":1,}`,
+		`{1:1}`,
+		`[}`,
+		`{]`,
+		`]`,
+		`1 2`,
+		`1,2`,
+		`"abc`,
+		`tru`,
+		`nul`,
+		`01`,
+		`-`,
+		`[1}`,
+		`{"a":1]`,
+		`{} {}`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if _, err := readTokens(test); err == nil {
+				t.Errorf("Expected error")
+			}
+			if _, err := Unmarshal([]byte(test)); err == nil {
+				t.Errorf("Expected error from Unmarshal")
+			}
+		})
+	}
+}
+
+func TestTokenizer_Next_suite(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "y_array_arraysWithSpaces", input: `[[]   ]`},
+		{name: "y_array_empty-string", input: `[""]`},
+		{name: "y_number_real_fraction_exponent", input: `[123.456e78]`},
+		{name: "y_number_real_neg_exp", input: `[1e-2]`},
+		{name: "y_object_duplicated_key", input: `{"a":"b","a":"c"}`},
+		{name: "y_string_allowed_escapes", input: `["\"\\\/\b\f\n\r\t"]`},
+		{name: "y_string_uEscape", input: `["\u0061\u30af\u30EA\u30b9"]`},
+		{name: "y_string_surrogates_U+1D11E_MUSICAL_SYMBOL_G_CLEF", input: `["\uD834\uDd1e"]`},
+		{name: "y_string_u+2028_line_sep", input: "[\"\u2028\"]"},
+		{name: "y_string_unescaped_char_delete", input: "[\"\x7f\"]"},
+		{name: "y_structure_lonely_negative_real", input: `-0.1`},
+		{name: "y_structure_trailing_newline", input: "[\"a\"]\n"},
+		{name: "y_structure_whitespace_array", input: ` [] `},
+		{name: "n_array_inner_array_no_comma", input: `[3[4]]`, wantErr: true},
+		{name: "n_array_just_comma", input: `[,]`, wantErr: true},
+		{name: "n_array_number_and_comma", input: `[1,]`, wantErr: true},
+		{name: "n_incomplete_true", input: `[tru]`, wantErr: true},
+		{name: "n_number_0.e1", input: `[0.e1]`, wantErr: true},
+		{name: "n_number_2.e3", input: `[2.e3]`, wantErr: true},
+		{name: "n_number_Inf", input: `[Inf]`, wantErr: true},
+		{name: "n_number_invalid+-", input: `[0e+-1]`, wantErr: true},
+		{name: "n_number_neg_real_without_int_part", input: `[-.123]`, wantErr: true},
+		{name: "n_object_missing_colon", input: `{"a" b}`, wantErr: true},
+		{name: "n_object_single_quote", input: `{'a':0}`, wantErr: true},
+		{name: "n_object_unquoted_key", input: `{a: "b"}`, wantErr: true},
+		{name: "n_string_escaped_backslash_bad", input: `["\\\"]`, wantErr: true},
+		{name: "n_string_invalid_utf8_after_escape", input: "[\"\\\xe5\"]", wantErr: true},
+		{name: "n_string_single_quote", input: `['single quote']`, wantErr: true},
+		{name: "n_string_unescaped_crtl_char", input: "[\"a\x00a\"]", wantErr: true},
+		{name: "n_structure_array_with_unclosed_string", input: `["asd]`, wantErr: true},
+		{name: "n_structure_capitalized_True", input: `[True]`, wantErr: true},
+		{name: "n_structure_comma_instead_of_closing_brace", input: `{"x": true,`, wantErr: true},
+		{name: "n_structure_double_array", input: `[][]`, wantErr: true},
+		{name: "n_structure_number_with_trailing_garbage", input: `2@`, wantErr: true},
+		{name: "n_structure_open_object_open_string", input: `{"a`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := readTokens(test.input); (err != nil) != test.wantErr {
+				t.Errorf("Tokenizer.Next() error = %v, wantErr %v.", err, test.wantErr)
+			}
+			if _, err := Unmarshal([]byte(test.input)); (err != nil) != test.wantErr {
+				t.Errorf("Unmarshal() error = %v, wantErr %v.", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenizer_Next_error(t *testing.T) {
+	tokenizer := NewTokenizer([]byte(`[1}`))
+	for i := 0; i < 2; i++ {
+		if _, err := tokenizer.Next(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	_, err := tokenizer.Next()
+	if err == nil || err == io.EOF {
+		t.Errorf("Expected error, got: %v", err)
+		return
+	}
+	if e, ok := err.(Error); !ok || e.Type != WrongSymbol || e.Index != 2 {
+		t.Errorf("Wrong error: %#v", err)
+	}
+	if _, next := tokenizer.Next(); next != err {
+		t.Errorf("Expected the same error, got: %v", next)
+	}
+}
+
+func TestTokenizer_example(t *testing.T) {
+	tokenizer := NewTokenizer(jsonExample)
+	counts := make(map[TokenType]int)
+	depth := 0
+	for {
+		token, err := tokenizer.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		counts[token.Type]++
+		if tokenizer.Depth() > depth {
+			depth = tokenizer.Depth()
+		}
+	}
+	root := Must(Unmarshal(jsonExample))
+	nodes, _ := root.JSONPath("$..*")
+	expected := map[TokenType]int{}
+	for _, node := range append(nodes, root) {
+		if node.Parent() != nil && node.Parent().IsObject() {
+			expected[TokenKey]++
+		}
+		switch node.Type() {
+		case Object:
+			expected[TokenBeginObject]++
+			expected[TokenEndObject]++
+		case Array:
+			expected[TokenBeginArray]++
+			expected[TokenEndArray]++
+		case String:
+			expected[TokenString]++
+		case Numeric:
+			expected[TokenNumber]++
+		}
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Wrong counts: %v, expected: %v", counts, expected)
+	}
+	if depth != 4 {
+		t.Errorf("Wrong depth: %d", depth)
+	}
+}
+
+func TestToken_Value(t *testing.T) {
+	tests := []struct {
+		token    Token
+		expected interface{}
+		err      bool
+	}{
+		{token: Token{Type: TokenKey, Raw: []byte(`"a\nb"`)}, expected: "a\nb"},
+		{token: Token{Type: TokenString, Raw: []byte(`"A"`)}, expected: "A"},
+		{token: Token{Type: TokenString, Raw: []byte(`"\x"`)}, err: true},
+		{token: Token{Type: TokenNumber, Raw: []byte(`-1.5e1`)}, expected: float64(-15)},
+		{token: Token{Type: TokenNumber, Raw: []byte(`x`)}, err: true},
+		{token: Token{Type: TokenBool, Raw: []byte(`true`)}, expected: true},
+		{token: Token{Type: TokenBool, Raw: []byte(`false`)}, expected: false},
+		{token: Token{Type: TokenNull, Raw: []byte(`null`)}, expected: nil},
+		{token: Token{Type: TokenBeginArray, Raw: []byte(`[`)}, expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(string(test.token.Raw), func(t *testing.T) {
+			value, err := test.token.Value()
+			if test.err {
+				if err == nil {
+					t.Errorf("Expected error, got: %v", value)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if value != test.expected {
+				t.Errorf("Wrong value: %#v, expected: %#v", value, test.expected)
+			}
+		})
+	}
+}
+
+func TestTokenType_String(t *testing.T) {
+	tests := map[TokenType]string{
+		TokenBeginObject: "begin-object",
+		TokenEndObject:   "end-object",
+		TokenBeginArray:  "begin-array",
+		TokenEndArray:    "end-array",
+		TokenKey:         "key",
+		TokenString:      "string",
+		TokenNumber:      "number",
+		TokenBool:        "bool",
+		TokenNull:        "null",
+		TokenType(-1):    "unknown",
+	}
+	for _type, expected := range tests {
+		if _type.String() != expected {
+			t.Errorf("Wrong string: %s, expected: %s", _type, expected)
+		}
+	}
+}
+
+func BenchmarkTokenizer_Next(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tokenizer := NewTokenizer(jsonExample)
+		for {
+			if _, err := tokenizer.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ajson
 
 import (
+	"strconv"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -154,3 +155,87 @@ func unquoteBytes(s []byte, border byte) (t []byte, ok bool) {
 	}
 	return b[0:w], true
 }
+
+// unquoteRelaxed converts a JSON5 string literal s, quoted with single or double quotes, into an actual string t.
+func unquoteRelaxed(s []byte) (t string, ok bool) {
+	if len(s) < 2 || (s[0] != quotes && s[0] != quote) || s[len(s)-1] != s[0] {
+		return
+	}
+	s = s[1 : len(s)-1]
+	b := make([]byte, 0, len(s))
+	for r := 0; r < len(s); {
+		c := s[r]
+		if c != '\\' {
+			b = append(b, c)
+			r++
+			continue
+		}
+		r++
+		if r >= len(s) {
+			return
+		}
+		switch c = s[r]; c {
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case 'v':
+			b = append(b, '\v')
+		case '0':
+			if r+1 < len(s) && s[r+1] >= '0' && s[r+1] <= '9' {
+				return
+			}
+			b = append(b, 0)
+		case 'x':
+			if r+2 >= len(s) {
+				return
+			}
+			value, err := strconv.ParseUint(string(s[r+1:r+3]), 16, 8)
+			if err != nil {
+				return
+			}
+			b = append(b, string(rune(value))...)
+			r += 2
+		case 'u':
+			rr := getu4(s[r-1:])
+			if rr < 0 {
+				return
+			}
+			r += 4
+			if utf16.IsSurrogate(rr) {
+				if rr1 := getu4(s[r+1:]); rr1 >= 0 {
+					if dec := utf16.DecodeRune(rr, rr1); dec != unicode.ReplacementChar {
+						rr = dec
+						r += 6
+					}
+				}
+			}
+			b = append(b, string(rr)...)
+		case '\r':
+			// line continuation
+			if r+1 < len(s) && s[r+1] == '\n' {
+				r++
+			}
+		case '\n':
+			// line continuation
+		default:
+			if c >= '1' && c <= '9' {
+				return
+			}
+			rr, size := utf8.DecodeRune(s[r:])
+			// line continuation with LS or PS is skipped, any other symbol is escaped to itself
+			if rr != '\u2028' && rr != '\u2029' {
+				b = append(b, s[r:r+size]...)
+			}
+			r += size - 1
+		}
+		r++
+	}
+	return string(b), true
+}
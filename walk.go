@@ -0,0 +1,70 @@
+package ajson
+
+// WalkAction is a result of the WalkFunc, that controls the traversal
+type WalkAction int
+
+const (
+	// WalkContinue continues the traversal
+	WalkContinue WalkAction = iota
+	// WalkSkip skips all children of the current node, makes sense for the pre-order traversal only
+	WalkSkip
+	// WalkStop stops the traversal
+	WalkStop
+)
+
+// WalkFunc is the type of the function called for each node visited by Walk and WalkPostOrder.
+// Depth of the starting node is 0.
+type WalkFunc func(node *Node, depth int) WalkAction
+
+// Walk traverses the tree, rooted at current node, in pre-order: each node is visited before its children.
+// Children are visited in order of indexes and keys in order of appearance. Children are taken before the first of
+// them is visited: the function can remove or add nodes, removed children are skipped and added ones are not visited.
+// Example:
+//
+//	root.Walk(func(node *Node, depth int) WalkAction {
+//		if node.Key() == "password" {
+//			_ = node.SetString("***")
+//			return WalkSkip
+//		}
+//		return WalkContinue
+//	})
+//
+func (n *Node) Walk(fn WalkFunc) {
+	if n == nil {
+		return
+	}
+	n.walk(fn, 0, false)
+}
+
+// WalkPostOrder traverses the tree, rooted at current node, in post-order: each node is visited after its children.
+// Children are visited in order of indexes and keys in order of appearance, changes of siblings are handled as by Walk.
+func (n *Node) WalkPostOrder(fn WalkFunc) {
+	if n == nil {
+		return
+	}
+	n.walk(fn, 0, true)
+}
+
+func (n *Node) walk(fn WalkFunc, depth int, post bool) WalkAction {
+	if !post {
+		if action := fn(n, depth); action != WalkContinue {
+			return action
+		}
+	}
+	// children are copied, so the function can change siblings: removed ones are skipped, added ones are not visited
+	children := append([]*Node(nil), n.children...)
+	for _, child := range children {
+		if child.parent != n {
+			continue
+		}
+		if child.walk(fn, depth+1, post) == WalkStop {
+			return WalkStop
+		}
+	}
+	if post {
+		if action := fn(n, depth); action == WalkStop {
+			return WalkStop
+		}
+	}
+	return WalkContinue
+}
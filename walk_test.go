@@ -0,0 +1,183 @@
+package ajson
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNode_Walk(t *testing.T) {
+	data := []byte(`{"b": [1, {"c": null}], "a": {"d": true, "e": "f"}, "g": 2}`)
+	tests := []struct {
+		name     string
+		action   func(node *Node, depth int) WalkAction
+		post     bool
+		expected string
+	}{
+		{
+			name:     "pre-order",
+			expected: `$:0 $['b']:1 $['b'][0]:2 $['b'][1]:2 $['b'][1]['c']:3 $['a']:1 $['a']['d']:2 $['a']['e']:2 $['g']:1`,
+		},
+		{
+			name:     "post-order",
+			post:     true,
+			expected: `$['b'][0]:2 $['b'][1]['c']:3 $['b'][1]:2 $['b']:1 $['a']['d']:2 $['a']['e']:2 $['a']:1 $['g']:1 $:0`,
+		},
+		{
+			name: "skip",
+			action: func(node *Node, depth int) WalkAction {
+				if node.IsArray() {
+					return WalkSkip
+				}
+				return WalkContinue
+			},
+			expected: `$:0 $['b']:1 $['a']:1 $['a']['d']:2 $['a']['e']:2 $['g']:1`,
+		},
+		{
+			name: "skip post-order",
+			action: func(node *Node, depth int) WalkAction {
+				return WalkSkip
+			},
+			post:     true,
+			expected: `$['b'][0]:2 $['b'][1]['c']:3 $['b'][1]:2 $['b']:1 $['a']['d']:2 $['a']['e']:2 $['a']:1 $['g']:1 $:0`,
+		},
+		{
+			name: "stop",
+			action: func(node *Node, depth int) WalkAction {
+				if node.IsNull() {
+					return WalkStop
+				}
+				return WalkContinue
+			},
+			expected: `$:0 $['b']:1 $['b'][0]:2 $['b'][1]:2 $['b'][1]['c']:3`,
+		},
+		{
+			name: "stop post-order",
+			action: func(node *Node, depth int) WalkAction {
+				if node.Key() == "a" {
+					return WalkStop
+				}
+				return WalkContinue
+			},
+			post:     true,
+			expected: `$['b'][0]:2 $['b'][1]['c']:3 $['b'][1]:2 $['b']:1 $['a']['d']:2 $['a']['e']:2 $['a']:1`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal(data))
+			result := make([]string, 0)
+			fn := func(node *Node, depth int) WalkAction {
+				result = append(result, node.Path()+":"+strconv.Itoa(depth))
+				if test.action != nil {
+					return test.action(node, depth)
+				}
+				return WalkContinue
+			}
+			if test.post {
+				root.WalkPostOrder(fn)
+			} else {
+				root.Walk(fn)
+			}
+			if strings.Join(result, " ") != test.expected {
+				t.Errorf("Wrong result: %s", strings.Join(result, " "))
+			}
+		})
+	}
+}
+
+func TestNode_Walk_subtree(t *testing.T) {
+	root := Must(Unmarshal([]byte(`{"a": {"b": [1, 2]}}`)))
+	count := 0
+	root.MustKey("a").Walk(func(node *Node, depth int) WalkAction {
+		if node.IsNumeric() && depth != 2 {
+			t.Errorf("Wrong depth: %d", depth)
+		}
+		count++
+		return WalkContinue
+	})
+	if count != 4 {
+		t.Errorf("Wrong count: %d", count)
+	}
+}
+
+func TestNode_Walk_mutation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		action   func(node *Node)
+		expected string
+		result   string
+	}{
+		{
+			name:  "delete next",
+			input: `[1, 2, 3, 4]`,
+			action: func(node *Node) {
+				if node.MustNumeric() == 2 {
+					_ = node.Parent().DeleteIndex(2)
+				}
+			},
+			expected: `1 2 4`,
+			result:   `[1,2,4]`,
+		},
+		{
+			name:  "delete self",
+			input: `[1, 2, 3, 4]`,
+			action: func(node *Node) {
+				if node.MustNumeric() == 2 {
+					_ = node.Parent().DeleteIndex(1)
+				}
+			},
+			expected: `1 2 3 4`,
+			result:   `[1,3,4]`,
+		},
+		{
+			name:  "delete key",
+			input: `{"a": 1, "b": 2, "c": 3}`,
+			action: func(node *Node) {
+				if node.Key() == "a" {
+					_ = node.Parent().DeleteKey("b")
+				}
+			},
+			expected: `1 3`,
+			result:   `{"a":1,"c":3}`,
+		},
+		{
+			name:  "append",
+			input: `[1, 2]`,
+			action: func(node *Node) {
+				_ = node.Parent().AppendArray(NumericNode("", 0))
+			},
+			expected: `1 2`,
+			result:   `[1,2,0,0]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := Must(Unmarshal([]byte(test.input)))
+			result := make([]string, 0)
+			root.Walk(func(node *Node, depth int) WalkAction {
+				if node.IsNumeric() {
+					result = append(result, node.String())
+					test.action(node)
+				}
+				return WalkContinue
+			})
+			if strings.Join(result, " ") != test.expected {
+				t.Errorf("Wrong visits: %s, expected: %s", strings.Join(result, " "), test.expected)
+			}
+			if ok, _ := root.Eq(Must(Unmarshal([]byte(test.result)))); !ok {
+				t.Errorf("Wrong result: %s, expected: %s", root, test.result)
+			}
+		})
+	}
+}
+
+func TestNode_Walk_nil(t *testing.T) {
+	fn := func(node *Node, depth int) WalkAction {
+		t.Errorf("Unexpected call")
+		return WalkContinue
+	}
+	(*Node)(nil).Walk(fn)
+	(*Node)(nil).WalkPostOrder(fn)
+}
@@ -0,0 +1,384 @@
+package ajson
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// XMLConvention is the convention of XML elements presentation in JSON
+type XMLConvention int
+
+const (
+	// XMLAttributes is the default convention: attributes become "@name" members, text of elements with attributes or
+	// children becomes the "#text" member, elements with text only become strings.
+	//
+	//	<a id="1"><b>x</b><b>y</b>text</a> => {"a":{"@id":"1","b":["x","y"],"#text":"text"}}
+	//
+	XMLAttributes XMLConvention = iota
+	// XMLBadgerFish is the BadgerFish convention: every element becomes the object, attributes become "@name"
+	// members, text becomes the "$" member, namespace declarations become the "@xmlns" object with the "$" member for
+	// the default namespace.
+	//
+	//	<a id="1" xmlns="urn:x"><b>x</b>text</a> => {"a":{"@id":"1","@xmlns":{"$":"urn:x"},"b":{"$":"x"},"$":"text"}}
+	//
+	XMLBadgerFish
+)
+
+// XMLOptions are options of the XML conversion
+type XMLOptions struct {
+	// Convention is the presentation of elements, XMLAttributes by default
+	Convention XMLConvention
+	// Arrays are names of elements, which always become arrays, even if they occur once
+	Arrays []string
+	// Coerce converts text and attribute values, which are JSON numbers or booleans, into numerics and bools in FromXML
+	Coerce bool
+	// Root is the name of the root element in ToXML, if the node is not an object with the single member, "root" by default
+	Root string
+	// Indent is the indentation of nested elements in ToXML, no indentation by default
+	Indent string
+}
+
+// xmlFrame is the element in process of FromXML
+type xmlFrame struct {
+	name   string
+	node   *Node
+	text   []string
+	buffer []byte
+}
+
+// FromXML converts the XML document into the object with the single member: the root element. Elements are converted
+// by the convention of options, repeated elements become arrays, prefixes of names are kept as is. Text is trimmed,
+// text parts split by children are joined with the space. Comments and processing instructions are skipped.
+// Example:
+//
+//	root, _ := FromXML(strings.NewReader(`<a id="1"><b>x</b><b>y</b></a>`), XMLOptions{})
+//	// root.String() == `{"a":{"@id":"1","b":["x","y"]}}`
+//
+func FromXML(r io.Reader, options XMLOptions) (*Node, error) {
+	decoder := xml.NewDecoder(r)
+	arrays := make(map[string]bool, len(options.Arrays))
+	for _, name := range options.Arrays {
+		arrays[name] = true
+	}
+	result := ObjectNode("", nil)
+	stack := []*xmlFrame{{node: result}}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		current := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 1 && len(result.children) != 0 {
+				return nil, errorRequest("multiple root elements")
+			}
+			current.flush()
+			frame := &xmlFrame{name: xmlName(token.Name), node: ObjectNode("", nil)}
+			for _, attr := range token.Attr {
+				if err = options.xmlAttr(frame.node, attr); err != nil {
+					return nil, err
+				}
+			}
+			stack = append(stack, frame)
+		case xml.EndElement:
+			if len(stack) == 1 || current.name != xmlName(token.Name) {
+				return nil, errorRequest("unexpected end element </%s>", xmlName(token.Name))
+			}
+			stack = stack[:len(stack)-1]
+			current.flush()
+			if err = xmlAppend(stack[len(stack)-1].node, current.name, options.xmlValue(current), arrays[current.name]); err != nil {
+				return nil, err
+			}
+		case xml.CharData:
+			if len(stack) == 1 {
+				if len(bytes.TrimSpace(token)) != 0 {
+					return nil, errorRequest("text outside of the root element")
+				}
+				continue
+			}
+			current.buffer = append(current.buffer, token...)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, errorRequest("element <%s> is not closed", stack[len(stack)-1].name)
+	}
+	if len(result.children) == 0 {
+		return nil, errorRequest("no root element")
+	}
+	return result, nil
+}
+
+// ToXML converts the node into the XML document, it's the reverse of FromXML with the same options. The object with
+// the single member becomes the root element, other values are written into the root element of options. Members of
+// objects become child elements, arrays become repeated elements, null becomes the empty element.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"a": {"@id": 1, "b": ["x", "y"], "#text": "z"}}`)))
+//	result, _ := ToXML(root, XMLOptions{})
+//	// string(result) == `<a id="1">z<b>x</b><b>y</b></a>`
+//
+func ToXML(node *Node, options XMLOptions) ([]byte, error) {
+	if node == nil {
+		return nil, errorUnparsed()
+	}
+	buf := new(bytes.Buffer)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", options.Indent)
+	var err error
+	if node.IsObject() && len(node.children) == 1 && node.children[0] != nil && !node.children[0].IsArray() &&
+		!options.isXMLSpecial(node.children[0].Key()) {
+		err = options.appendXML(encoder, node.children[0].Key(), node.children[0])
+	} else if node.IsArray() {
+		// items of the array are written as repeated <item> elements of the root element
+		start := xml.StartElement{Name: xml.Name{Local: options.xmlRoot()}}
+		if err = encoder.EncodeToken(start); err == nil {
+			if err = options.appendXML(encoder, "item", node); err == nil {
+				err = encoder.EncodeToken(start.End())
+			}
+		}
+	} else {
+		err = options.appendXML(encoder, options.xmlRoot(), node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flush appends the trimmed text of the buffer to the text parts
+func (f *xmlFrame) flush() {
+	if text := strings.TrimSpace(string(f.buffer)); text != "" {
+		f.text = append(f.text, text)
+	}
+	f.buffer = f.buffer[:0]
+}
+
+// xmlName returns the name of the element or the attribute with the prefix
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlAppend appends the value of the element to the parent object, repeated elements become arrays
+func xmlAppend(parent *Node, name string, value *Node, array bool) error {
+	existing, ok := parent.members[name]
+	if !ok {
+		if array {
+			value = ArrayNode("", []*Node{value})
+		}
+		return parent.AppendObject(name, value)
+	}
+	// values of elements are never arrays, so the array is the result of the previous repetition
+	if !existing.IsArray() {
+		existing = ArrayNode("", []*Node{existing.Clone()})
+		if err := parent.AppendObject(name, existing); err != nil {
+			return err
+		}
+	}
+	return existing.AppendArray(value)
+}
+
+// xmlAttr appends the attribute to the object of the element
+func (o XMLOptions) xmlAttr(node *Node, attr xml.Attr) error {
+	name := xmlName(attr.Name)
+	if o.Convention == XMLBadgerFish && (name == "xmlns" || attr.Name.Space == "xmlns") {
+		namespaces, ok := node.members["@xmlns"]
+		if !ok {
+			namespaces = ObjectNode("", nil)
+			if err := node.AppendObject("@xmlns", namespaces); err != nil {
+				return err
+			}
+		}
+		key := attr.Name.Local
+		if name == "xmlns" {
+			key = "$"
+		}
+		return namespaces.AppendObject(key, StringNode("", attr.Value))
+	}
+	return node.AppendObject("@"+name, o.xmlScalar(attr.Value))
+}
+
+// xmlValue returns the value of the element by the convention
+func (o XMLOptions) xmlValue(frame *xmlFrame) *Node {
+	text := strings.Join(frame.text, " ")
+	if o.Convention == XMLBadgerFish {
+		if text != "" {
+			_ = frame.node.AppendObject("$", o.xmlScalar(text))
+		}
+		return frame.node
+	}
+	if len(frame.node.children) == 0 {
+		return o.xmlScalar(text)
+	}
+	if text != "" {
+		_ = frame.node.AppendObject("#text", o.xmlScalar(text))
+	}
+	return frame.node
+}
+
+// xmlScalar returns the node of the text value
+func (o XMLOptions) xmlScalar(text string) *Node {
+	if o.Coerce {
+		if node, err := Unmarshal([]byte(text)); err == nil && (node.IsNumeric() || node.IsBool()) {
+			return node
+		}
+	}
+	return StringNode("", text)
+}
+
+// appendXML writes the value as the element with the name, arrays are written as repeated elements
+func (o XMLOptions) appendXML(encoder *xml.Encoder, name string, node *Node) error {
+	if node == nil {
+		return errorUnparsed()
+	}
+	if !isXMLName(name) {
+		return errorRequest("wrong XML name %q", name)
+	}
+	if node.IsArray() {
+		for _, child := range node.children {
+			if child != nil && child.IsArray() {
+				return errorRequest("nested array in element <%s>", name)
+			}
+			if err := o.appendXML(encoder, name, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !node.IsObject() {
+		text, err := scalarText(node)
+		if err != nil {
+			return err
+		}
+		return xmlElement(encoder, start, text)
+	}
+
+	textKey := o.xmlTextKey()
+	var text string
+	children := make([]*Node, 0, len(node.children))
+	for _, child := range node.children {
+		if child == nil {
+			return errorUnparsed()
+		}
+		key := child.Key()
+		switch {
+		case key == textKey:
+			value, err := xmlText(child, key)
+			if err != nil {
+				return err
+			}
+			text = value
+		case o.Convention == XMLBadgerFish && key == "@xmlns" && child.IsObject():
+			for _, namespace := range child.children {
+				value, err := xmlText(namespace, key)
+				if err != nil {
+					return err
+				}
+				attr := "xmlns:" + namespace.Key()
+				if namespace.Key() == "$" {
+					attr = "xmlns"
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: value})
+			}
+		case strings.HasPrefix(key, "@"):
+			if !isXMLName(key[1:]) {
+				return errorRequest("wrong XML name %q", key[1:])
+			}
+			value, err := xmlText(child, key)
+			if err != nil {
+				return err
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key[1:]}, Value: value})
+		default:
+			children = append(children, child)
+		}
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range children {
+		if err := o.appendXML(encoder, child.Key(), child); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlElement writes the element with the text content
+func xmlElement(encoder *xml.Encoder, start xml.StartElement, text string) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlText returns the text of the attribute or the text member, which should be scalar
+func xmlText(node *Node, key string) (string, error) {
+	if node == nil {
+		return "", errorUnparsed()
+	}
+	if node.isContainer() {
+		return "", errorRequest("member %q should be scalar", key)
+	}
+	return scalarText(node)
+}
+
+// isXMLSpecial checks if the key is the attribute or the text member
+func (o XMLOptions) isXMLSpecial(key string) bool {
+	return strings.HasPrefix(key, "@") || key == o.xmlTextKey()
+}
+
+func (o XMLOptions) xmlTextKey() string {
+	if o.Convention == XMLBadgerFish {
+		return "$"
+	}
+	return "#text"
+}
+
+func (o XMLOptions) xmlRoot() string {
+	if o.Root == "" {
+		return "root"
+	}
+	return o.Root
+}
+
+// isXMLName checks if the name is the valid XML name: a letter or '_' followed by letters, digits, '-', '.', '_',
+// the single ':' separates the prefix
+func isXMLName(name string) bool {
+	if name == "" || strings.Count(name, ":") > 1 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || unicode.IsLetter(c):
+		case i > 0 && (c == '-' || c == '.' || unicode.IsDigit(c)):
+		case c == ':' && i > 0 && i < len(name)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package ajson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  XMLOptions
+		expected string
+	}{
+		{name: "text", input: `<a>x</a>`, expected: `{"a":"x"}`},
+		{name: "empty", input: `<?xml version="1.0"?><!-- comment --><a/>`, expected: `{"a":""}`},
+		{
+			name:     "attributes",
+			input:    `<a id="1"><b>x</b><b>y</b>text</a>`,
+			expected: `{"a":{"@id":"1","b":["x","y"],"#text":"text"}}`,
+		},
+		{
+			name:     "mixed",
+			input:    "<a>\n  x <![CDATA[<y>]]>\n  <b/>\n  z &amp; w\n</a>",
+			expected: `{"a":{"b":"","#text":"x \u003cy\u003e z \u0026 w"}}`,
+		},
+		{
+			name:     "repeated",
+			input:    `<a><b>1</b><c>2</c><b><d>3</d></b><b>4</b></a>`,
+			expected: `{"a":{"b":["1",{"d":"3"},"4"],"c":"2"}}`,
+		},
+		{
+			name:     "prefixes",
+			input:    `<s:a xmlns:s="urn:s" xml:lang="en"><s:b>1</s:b></s:a>`,
+			expected: `{"s:a":{"@xmlns:s":"urn:s","@xml:lang":"en","s:b":"1"}}`,
+		},
+		{
+			name:     "arrays",
+			input:    `<a><b>1</b><c>2</c></a>`,
+			options:  XMLOptions{Arrays: []string{"b", "a"}},
+			expected: `{"a":[{"b":["1"],"c":"2"}]}`,
+		},
+		{
+			name:     "coerce",
+			input:    `<a n="-1.5e2" s="007"><b>true</b><c>null</c><d>1 2</d></a>`,
+			options:  XMLOptions{Coerce: true},
+			expected: `{"a":{"@n":-1.5e2,"@s":"007","b":true,"c":"null","d":"1 2"}}`,
+		},
+		{
+			name:     "badgerfish",
+			input:    `<a id="1" xmlns="urn:x" xmlns:s="urn:s"><b>x</b><b/>text</a>`,
+			options:  XMLOptions{Convention: XMLBadgerFish},
+			expected: `{"a":{"@id":"1","@xmlns":{"$":"urn:x","s":"urn:s"},"b":[{"$":"x"},{}],"$":"text"}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := FromXML(strings.NewReader(test.input), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if result := root.String(); result != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestFromXML_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "blank", input: ``, err: "wrong request: no root element"},
+		{name: "multiple", input: `<a/><b/>`, err: "wrong request: multiple root elements"},
+		{name: "text", input: `<a/>b`, err: "wrong request: text outside of the root element"},
+		{name: "mismatch", input: `<a></b>`, err: "wrong request: unexpected end element </b>"},
+		{name: "not closed", input: `<a><b>`, err: "wrong request: element <b> is not closed"},
+		{name: "syntax", input: `<a x=1/>`, err: "XML syntax error on line 1: unquoted or missing attribute value in element"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromXML(strings.NewReader(test.input), XMLOptions{})
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestToXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  XMLOptions
+		expected string
+	}{
+		{name: "text", input: `{"a": "x < y"}`, expected: `<a>x &lt; y</a>`},
+		{
+			name:     "attributes",
+			input:    `{"a": {"@id": 1, "b": ["x", null, {"c": true}], "#text": "z"}}`,
+			expected: `<a id="1">z<b>x</b><b></b><b><c>true</c></b></a>`,
+		},
+		{name: "root", input: `{"a": 1, "b": 2.50}`, expected: `<root><a>1</a><b>2.50</b></root>`},
+		{name: "array", input: `[1, {"a": 2}]`, options: XMLOptions{Root: "list"}, expected: `<list><item>1</item><item><a>2</a></item></list>`},
+		{name: "scalar", input: `"x"`, expected: `<root>x</root>`},
+		{name: "attribute root", input: `{"@id": 1}`, expected: `<root id="1"></root>`},
+		{
+			name:     "badgerfish",
+			input:    `{"a": {"@xmlns": {"$": "urn:x", "s": "urn:s"}, "s:b": {"$": "x"}, "$": "y"}}`,
+			options:  XMLOptions{Convention: XMLBadgerFish},
+			expected: `<a xmlns="urn:x" xmlns:s="urn:s">y<s:b>x</s:b></a>`,
+		},
+		{
+			name:     "indent",
+			input:    `{"a": {"b": [1, 2]}}`,
+			options:  XMLOptions{Indent: "  "},
+			expected: "<a>\n  <b>1</b>\n  <b>2</b>\n</a>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ToXML(Must(Unmarshal([]byte(test.input))), test.options)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestToXML_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "name", input: `{"a b": 1}`, err: `wrong request: wrong XML name "a b"`},
+		{name: "digit", input: `{"a": {"1": 1}}`, err: `wrong request: wrong XML name "1"`},
+		{name: "attribute", input: `{"a": {"@": 1}}`, err: `wrong request: wrong XML name ""`},
+		{name: "attribute value", input: `{"a": {"@b": [1]}}`, err: `wrong request: member "@b" should be scalar`},
+		{name: "nested array", input: `{"a": {"b": [[1]]}}`, err: "wrong request: nested array in element <b>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ToXML(Must(Unmarshal([]byte(test.input))), XMLOptions{})
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+	if _, err := ToXML(nil, XMLOptions{}); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestXML_roundTrip(t *testing.T) {
+	for _, convention := range []XMLConvention{XMLAttributes, XMLBadgerFish} {
+		input := `<feed xmlns="urn:a" xml:lang="en"><entry id="1"><title>a &amp; b</title></entry><entry id="2"><title>c</title></entry></feed>`
+		root, err := FromXML(strings.NewReader(input), XMLOptions{Convention: convention})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		result, err := ToXML(root, XMLOptions{Convention: convention})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		} else if string(result) != input {
+			t.Errorf("Wrong result for %d:\n%s\nexpected:\n%s", convention, result, input)
+		}
+	}
+}
@@ -0,0 +1,968 @@
+package ajson
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlFloat is the numeric of the YAML 1.2 core schema, integers are matched as well
+var yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+// yamlTags are types of values for the tags of the YAML 1.2 core schema
+var yamlTags = map[string]NodeType{
+	"!!null":  Null,
+	"!!bool":  Bool,
+	"!!int":   Numeric,
+	"!!float": Numeric,
+}
+
+// yamlBooleans are booleans of YAML 1.1, such strings are quoted for the compatibility with older parsers
+var yamlBooleans = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "n": true, "N": true, "no": true, "No": true,
+	"NO": true, "on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+// Limits of the YAML parser, which is recursive, and of aliases, which copy anchored nodes
+const (
+	// yamlMaxDepth is the maximum nesting level of nodes
+	yamlMaxDepth = 10000
+	// yamlMaxAliasNodes is the maximum total count of nodes, copied by aliases
+	yamlMaxAliasNodes = 1000000
+)
+
+// yamlMerge is the value of the merge key "<<" and the position of the key
+type yamlMerge struct {
+	start int
+	value *Node
+}
+
+// yamlParser is the recursive descent parser of the single YAML document
+type yamlParser struct {
+	data    []byte
+	pos     int
+	depth   int
+	aliased int
+	anchors map[string]*Node
+}
+
+// UnmarshalYAML parses the YAML document and returns the root node of struct. Mappings become objects, sequences
+// become arrays and scalars are resolved by the YAML 1.2 core schema: null, bool, numeric or string.
+// Anchors and aliases are supported, aliased nodes are copied. Merge keys "<<" add members of the mapping or
+// of the sequence of mappings, which are not in the mapping. Complex keys and multiple documents are not supported.
+// Nesting of nodes is limited by 10000 levels, error of type MaxDepthExceeded is returned; aliases can copy
+// 1000000 nodes in total.
+// Example:
+//
+//	root, _ := UnmarshalYAML([]byte("name: ajson\ntags: [json, yaml]\nversion: 1.0\n"))
+//	// root.String() == `{"name":"ajson","tags":["json","yaml"],"version":1}`
+//
+func UnmarshalYAML(data []byte) (root *Node, err error) {
+	p := &yamlParser{
+		data:    data,
+		anchors: make(map[string]*Node),
+	}
+	p.skipBlank()
+	for p.column() == 0 && p.peek(0) == '%' {
+		for !p.atEOL() {
+			p.pos++
+		}
+		p.skipBlank()
+	}
+	if p.marker() == "---" {
+		p.pos += 3
+	}
+	if root, err = p.value(-1, false); err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.marker() == "..." {
+		p.pos += 3
+		p.skipBlank()
+	}
+	if !p.eof() {
+		return nil, p.error()
+	}
+	return root, nil
+}
+
+// MarshalYAML returns the YAML document of the node in the block style, strings are quoted only if needed.
+// Example:
+//
+//	root := Must(Unmarshal([]byte(`{"name": "ajson", "tags": ["json", "yaml"], "empty": {}}`)))
+//	result, _ := MarshalYAML(root)
+//	// string(result) == "name: ajson\ntags:\n  - json\n  - yaml\nempty: {}\n"
+//
+func MarshalYAML(node *Node) (result []byte, err error) {
+	if result, err = appendYAML(make([]byte, 0), node, 0); err != nil {
+		return nil, err
+	}
+	return append(result, '\n'), nil
+}
+
+// value parses the node of the block context, indent is the indentation of the parent collection,
+// mapping is true for values of the mapping
+func (p *yamlParser) value(indent int, mapping bool) (node *Node, err error) {
+	defer p.leave()
+	if err = p.enter(); err != nil {
+		return nil, err
+	}
+	var anchor, tag string
+	for node == nil {
+		p.skipLine()
+		if p.atEOL() {
+			p.skipBlank()
+			column := p.column()
+			if p.eof() || p.marker() != "" || column < indent || column == indent && !(mapping && p.isEntry()) {
+				if node, err = p.scalar(p.pos, "", true, tag); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		if c := p.peek(0); c == '&' || c == '!' {
+			if property := p.property(); c == '&' {
+				anchor = property[1:]
+			} else {
+				tag = property
+			}
+			continue
+		}
+		if node, err = p.content(indent, mapping, tag); err != nil {
+			return nil, err
+		}
+	}
+	if anchor != "" {
+		p.anchors[anchor] = node
+	}
+	return node, nil
+}
+
+// content parses the content of the node of the block context
+func (p *yamlParser) content(indent int, mapping bool, tag string) (node *Node, err error) {
+	start := p.pos
+	// block collections can't start on the same line with the key of the mapping
+	block := !mapping || !p.inline()
+	switch c := p.peek(0); {
+	case p.isEntry():
+		if !block {
+			return nil, p.error()
+		}
+		return p.sequence(p.column())
+	case c == '|' || c == '>':
+		text, err := p.block(indent)
+		if err != nil {
+			return nil, err
+		}
+		return p.scalar(start, text, false, tag)
+	case c == '*':
+		return p.alias()
+	case c == '[' || c == '{':
+		return p.flow(indent)
+	case c == '?' && isYAMLSpace(p.peek(1)), c == '@', c == '`', c == ']', c == '}', c == ',':
+		return nil, p.error()
+	}
+
+	column := p.column()
+	text := ""
+	plain := !p.isQuote()
+	if plain {
+		text = p.plainLine(false)
+	} else if text, err = p.quoted(); err != nil {
+		return nil, err
+	}
+	end := p.pos
+	p.skipBlanks()
+	if p.peek(0) == ':' && isYAMLSpace(p.peek(1)) {
+		if !block || bytes.IndexByte(p.data[start:end], '\n') != -1 {
+			return nil, p.error()
+		}
+		return p.mapping(column, text, start)
+	}
+	p.pos = end
+	if plain {
+		text = p.plainRest(text, indent, false)
+	}
+	return p.scalar(start, text, plain, tag)
+}
+
+// mapping parses the block mapping, the first key is already parsed and the position is on its colon
+func (p *yamlParser) mapping(indent int, key string, start int) (*Node, error) {
+	node := ObjectNode("", nil)
+	var merges []yamlMerge
+	for {
+		merge := p.mergeKey(start, key)
+		if !merge && node.HasKey(key) {
+			return nil, errorDuplicate(start, key)
+		}
+		p.pos++
+		value, err := p.value(indent, true)
+		if err != nil {
+			return nil, err
+		}
+		if merge {
+			merges = append(merges, yamlMerge{start: start, value: value})
+		} else if err = node.AppendObject(key, value); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if p.eof() || p.marker() != "" || p.column() < indent {
+			return node, p.merge(node, merges)
+		}
+		if p.column() > indent || p.isEntry() {
+			return nil, p.error()
+		}
+		start = p.pos
+		if p.isQuote() {
+			if key, err = p.quoted(); err != nil {
+				return nil, err
+			}
+		} else if c := p.peek(0); c == '?' || c == '[' || c == '{' || c == '*' || c == '&' || c == '!' {
+			return nil, p.error()
+		} else {
+			key = p.plainLine(false)
+		}
+		p.skipBlanks()
+		if p.peek(0) != ':' || !isYAMLSpace(p.peek(1)) {
+			return nil, p.error()
+		}
+	}
+}
+
+// sequence parses the block sequence, the position is on the first entry indicator
+func (p *yamlParser) sequence(indent int) (*Node, error) {
+	node := ArrayNode("", nil)
+	for {
+		p.pos++
+		value, err := p.value(indent, false)
+		if err != nil {
+			return nil, err
+		}
+		if err = node.AppendArray(value); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if p.eof() || p.marker() != "" || p.column() < indent || p.column() == indent && !p.isEntry() {
+			return node, nil
+		}
+		if p.column() > indent {
+			return nil, p.error()
+		}
+	}
+}
+
+// flow parses the flow collection: [a, b] or {a: b}
+func (p *yamlParser) flow(indent int) (*Node, error) {
+	var node *Node
+	closing := byte(']')
+	if p.peek(0) == '[' {
+		node = ArrayNode("", nil)
+	} else {
+		node = ObjectNode("", nil)
+		closing = '}'
+	}
+	p.pos++
+	var merges []yamlMerge
+	for {
+		p.skipBlank()
+		if p.peek(0) == closing {
+			p.pos++
+			return node, p.merge(node, merges)
+		}
+		start := p.pos
+		value, key, err := p.flowNode(indent)
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		pair := p.peek(0) == ':'
+		if pair {
+			if value.isContainer() {
+				return nil, p.error()
+			}
+			p.pos++
+			p.skipBlank()
+			if c := p.peek(0); c == ',' || c == closing {
+				value = NullNode("")
+			} else if value, _, err = p.flowNode(indent); err != nil {
+				return nil, err
+			}
+			p.skipBlank()
+		}
+		if node.IsArray() {
+			if pair {
+				entry := ObjectNode("", nil)
+				_ = entry.AppendObject(key, value)
+				value = entry
+			}
+			err = node.AppendArray(value)
+		} else {
+			if !pair {
+				if value.isContainer() {
+					return nil, errorAt(start, p.data[start])
+				}
+				value = NullNode("")
+			}
+			if p.mergeKey(start, key) {
+				merges = append(merges, yamlMerge{start: start, value: value})
+			} else if node.HasKey(key) {
+				return nil, errorDuplicate(start, key)
+			} else {
+				err = node.AppendObject(key, value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c := p.peek(0); c == ',' {
+			p.pos++
+		} else if c != closing {
+			return nil, p.error()
+		}
+	}
+}
+
+// flowNode parses the node of the flow collection, text is the content of the scalar
+func (p *yamlParser) flowNode(indent int) (node *Node, text string, err error) {
+	defer p.leave()
+	if err = p.enter(); err != nil {
+		return nil, "", err
+	}
+	var anchor, tag string
+	for c := p.peek(0); c == '&' || c == '!'; c = p.peek(0) {
+		if property := p.property(); c == '&' {
+			anchor = property[1:]
+		} else {
+			tag = property
+		}
+		p.skipBlank()
+	}
+	start := p.pos
+	switch c := p.peek(0); {
+	case c == '[' || c == '{':
+		node, err = p.flow(indent)
+	case c == '*':
+		node, err = p.alias()
+	case p.isQuote():
+		if text, err = p.quoted(); err == nil {
+			node, err = p.scalar(start, text, false, tag)
+		}
+	case c == ',' || c == ']' || c == '}' || c == ':' && isYAMLSpace(p.peek(1)):
+		node, err = p.scalar(start, "", true, tag)
+	default:
+		if text = p.plainRest(p.plainLine(true), indent, true); text == "" {
+			return nil, "", p.error()
+		}
+		node, err = p.scalar(start, text, true, tag)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if anchor != "" {
+		p.anchors[anchor] = node
+	}
+	return node, text, nil
+}
+
+// mergeKey checks if the key at the start is the plain merge key "<<"
+func (p *yamlParser) mergeKey(start int, key string) bool {
+	return key == "<<" && p.data[start] == '<'
+}
+
+// merge adds members of merged mappings, which are not in the mapping yet: explicit keys take precedence,
+// mappings of the sequence take precedence over the next ones
+func (p *yamlParser) merge(node *Node, merges []yamlMerge) error {
+	for _, merge := range merges {
+		mappings := []*Node{merge.value}
+		if merge.value.IsArray() {
+			mappings = merge.value.children
+		}
+		for _, mapping := range mappings {
+			if !mapping.IsObject() {
+				return errorAt(merge.start, '<')
+			}
+			for _, child := range mapping.children {
+				if node.HasKey(child.Key()) {
+					continue
+				}
+				if err := node.AppendObject(child.Key(), child.Clone()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// alias returns the copy of the anchored node
+func (p *yamlParser) alias() (*Node, error) {
+	start := p.pos
+	node, ok := p.anchors[p.property()[1:]]
+	if !ok {
+		return nil, errorAt(start, '*')
+	}
+	node.Walk(func(*Node, int) WalkAction {
+		p.aliased++
+		return WalkContinue
+	})
+	if p.aliased > yamlMaxAliasNodes {
+		return nil, errorRequest("aliases copy more than %d nodes at %d", yamlMaxAliasNodes, start)
+	}
+	return node.Clone(), nil
+}
+
+// enter increases the nesting level, error of type MaxDepthExceeded is returned, if the level is deeper than
+// yamlMaxDepth; leave should be called after the node is parsed
+func (p *yamlParser) enter() error {
+	if p.depth++; p.depth > yamlMaxDepth {
+		return errorLimit(MaxDepthExceeded, p.pos, yamlMaxDepth)
+	}
+	return nil
+}
+
+// leave decreases the nesting level
+func (p *yamlParser) leave() {
+	p.depth--
+}
+
+// scalar returns the node of the scalar, plain scalars and scalars with the tag are resolved by the core schema
+func (p *yamlParser) scalar(start int, text string, plain bool, tag string) (*Node, error) {
+	_type, ok := yamlTags[tag]
+	if tag == "!" || tag == "!!str" || !plain && !ok {
+		return StringNode("", text), nil
+	}
+	node := yamlResolve(text)
+	if ok && node._type != _type {
+		return nil, errorAt(start, p.data[start])
+	}
+	return node, nil
+}
+
+// plainLine returns the plain scalar up to the end of the line or the indicator, which ends it
+func (p *yamlParser) plainLine(flow bool) string {
+	start, end := p.pos, p.pos
+	for !p.atEOL() {
+		c := p.data[p.pos]
+		if c == ':' && (isYAMLSpace(p.peek(1)) || flow && isFlowIndicator(p.peek(1))) ||
+			c == '#' && p.pos > start && isYAMLBlank(p.data[p.pos-1]) ||
+			flow && isFlowIndicator(c) {
+			break
+		}
+		p.pos++
+		if !isYAMLBlank(c) {
+			end = p.pos
+		}
+	}
+	p.pos = end
+	return string(p.data[start:end])
+}
+
+// plainRest appends the continuation lines of the multi-line plain scalar, which are indented more than indent
+func (p *yamlParser) plainRest(text string, indent int, flow bool) string {
+	for {
+		end := p.pos
+		p.skipBlanks()
+		if !p.atEOL() || p.eof() {
+			p.pos = end
+			return text
+		}
+		breaks := 0
+		for p.atEOL() && !p.eof() {
+			p.newline()
+			p.skipBlanks()
+			breaks++
+		}
+		line := ""
+		if !p.eof() && p.column() > indent && p.peek(0) != '#' && p.marker() == "" {
+			line = p.plainLine(flow)
+		}
+		if line == "" {
+			p.pos = end
+			return text
+		}
+		if breaks == 1 {
+			text += " "
+		} else {
+			text += strings.Repeat("\n", breaks-1)
+		}
+		text += line
+	}
+}
+
+// quoted returns the content of the single or double-quoted scalar
+func (p *yamlParser) quoted() (string, error) {
+	quote := p.data[p.pos]
+	p.pos++
+	result := make([]byte, 0)
+	// trimmed is the length of the result without trailing white spaces, which are not escaped
+	trimmed := 0
+	for {
+		if p.eof() {
+			return "", p.error()
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == quote && quote == '\'' && p.peek(1) == '\'':
+			result = append(result, '\'')
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return string(result), nil
+		case c == '\\' && quote == '"' && isYAMLBreak(p.peek(1)):
+			p.pos++
+			p.newline()
+			p.skipBlanks()
+		case c == '\\' && quote == '"':
+			r, size, ok := yamlEscape(p.data[p.pos+1:])
+			if !ok {
+				p.pos++
+				return "", p.error()
+			}
+			result = append(result, string(r)...)
+			p.pos += 1 + size
+		case isYAMLBreak(c):
+			result = result[:trimmed]
+			breaks := 0
+			for !p.eof() && isYAMLBreak(p.data[p.pos]) {
+				p.newline()
+				p.skipBlanks()
+				breaks++
+			}
+			if breaks == 1 {
+				result = append(result, ' ')
+			} else {
+				result = append(result, strings.Repeat("\n", breaks-1)...)
+			}
+		default:
+			result = append(result, c)
+			p.pos++
+			if isYAMLBlank(c) {
+				continue
+			}
+		}
+		trimmed = len(result)
+	}
+}
+
+// block returns the content of the literal (|) or folded (>) block scalar
+func (p *yamlParser) block(indent int) (string, error) {
+	literal := p.data[p.pos] == '|'
+	p.pos++
+	var chomping byte
+	explicit := 0
+	for i := 0; i < 2; i++ {
+		if c := p.peek(0); (c == '-' || c == '+') && chomping == 0 {
+			chomping = c
+			p.pos++
+		} else if c >= '1' && c <= '9' && explicit == 0 {
+			explicit = int(c - '0')
+			p.pos++
+		}
+	}
+	p.skipLine()
+	if !p.atEOL() {
+		return "", p.error()
+	}
+	content := -1
+	if explicit != 0 {
+		if content = indent + explicit; content < 0 {
+			content = 0
+		}
+	}
+	lines := make([]string, 0)
+	for !p.eof() {
+		p.newline()
+		if p.eof() {
+			break
+		}
+		start := p.pos
+		for p.peek(0) == ' ' {
+			p.pos++
+		}
+		spaces := p.pos - start
+		if p.atEOL() {
+			if content >= 0 && spaces > content {
+				lines = append(lines, string(p.data[start+content:p.pos]))
+			} else {
+				lines = append(lines, "")
+			}
+			continue
+		}
+		if content < 0 && spaces > indent {
+			content = spaces
+		}
+		if content < 0 || spaces < content || spaces == 0 && p.marker() != "" {
+			p.pos = start
+			break
+		}
+		p.pos = start + content
+		for !p.atEOL() {
+			p.pos++
+		}
+		lines = append(lines, string(p.data[start+content:p.pos]))
+	}
+
+	trailing := 0
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	text := yamlFold(lines, literal)
+	if len(lines) != 0 && chomping != '-' {
+		text += "\n"
+	}
+	if chomping == '+' {
+		text += strings.Repeat("\n", trailing)
+	}
+	return text, nil
+}
+
+// yamlFold joins lines of the block scalar: folded lines are joined with spaces, except empty and more-indented lines
+func yamlFold(lines []string, literal bool) string {
+	result := make([]byte, 0)
+	breaks := 0
+	previous := true
+	for i, line := range lines {
+		if line == "" {
+			breaks++
+			continue
+		}
+		more := line[0] == ' ' || line[0] == '\t'
+		switch {
+		case i == breaks:
+			result = append(result, strings.Repeat("\n", breaks)...)
+		case literal || more || previous:
+			result = append(result, strings.Repeat("\n", breaks+1)...)
+		case breaks == 0:
+			result = append(result, ' ')
+		default:
+			result = append(result, strings.Repeat("\n", breaks)...)
+		}
+		result = append(result, line...)
+		breaks = 0
+		previous = more
+	}
+	return string(result)
+}
+
+// yamlEscape returns the rune of the escape sequence of the double-quoted scalar, data starts after the backslash
+func yamlEscape(data []byte) (r rune, size int, ok bool) {
+	if len(data) == 0 {
+		return 0, 0, false
+	}
+	switch data[0] {
+	case '0':
+		return 0, 1, true
+	case 'a':
+		return '\a', 1, true
+	case 'b':
+		return '\b', 1, true
+	case 't', '\t':
+		return '\t', 1, true
+	case 'n':
+		return '\n', 1, true
+	case 'v':
+		return '\v', 1, true
+	case 'f':
+		return '\f', 1, true
+	case 'r':
+		return '\r', 1, true
+	case 'e':
+		return 0x1b, 1, true
+	case ' ', '"', '/', '\\':
+		return rune(data[0]), 1, true
+	case 'N':
+		return 0x85, 1, true
+	case '_':
+		return 0xa0, 1, true
+	case 'L':
+		return 0x2028, 1, true
+	case 'P':
+		return 0x2029, 1, true
+	case 'x':
+		size = 2
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return 0, 0, false
+	}
+	if len(data) <= size {
+		return 0, 0, false
+	}
+	value, err := strconv.ParseUint(string(data[1:1+size]), 16, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return rune(value), 1 + size, true
+}
+
+// yamlResolve returns the node of the plain scalar by the YAML 1.2 core schema
+func yamlResolve(text string) *Node {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return NullNode("")
+	case "true", "True", "TRUE":
+		return BoolNode("", true)
+	case "false", "False", "FALSE":
+		return BoolNode("", false)
+	case ".nan", ".NaN", ".NAN":
+		return NumericNode("", math.NaN())
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return NumericNode("", math.Inf(1))
+	case "-.inf", "-.Inf", "-.INF":
+		return NumericNode("", math.Inf(-1))
+	}
+	if yamlFloat.MatchString(text) {
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			return NumericNode("", value)
+		}
+	} else if len(text) > 2 && (text[:2] == "0o" || text[:2] == "0x") {
+		base := 8
+		if text[1] == 'x' {
+			base = 16
+		}
+		if value, err := strconv.ParseUint(text[2:], base, 64); err == nil {
+			return NumericNode("", float64(value))
+		}
+	}
+	return StringNode("", text)
+}
+
+// appendYAML appends the node in the block style, the first line is not indented
+func appendYAML(result []byte, node *Node, indent int) ([]byte, error) {
+	if node == nil || !node.dirty && !node.ready() {
+		return nil, errorUnparsed()
+	}
+	var err error
+	switch node._type {
+	case Null:
+		result = append(result, _null...)
+	case Numeric:
+		var value float64
+		if value, err = node.GetNumeric(); err != nil {
+			return nil, err
+		}
+		switch {
+		case math.IsNaN(value):
+			result = append(result, ".nan"...)
+		case math.IsInf(value, 1):
+			result = append(result, ".inf"...)
+		case math.IsInf(value, -1):
+			result = append(result, "-.inf"...)
+		default:
+			result = strconv.AppendFloat(result, value, 'g', -1, 64)
+		}
+	case String:
+		var value string
+		if value, err = node.GetString(); err != nil {
+			return nil, err
+		}
+		result = appendYAMLString(result, value)
+	case Bool:
+		var value bool
+		if value, err = node.GetBool(); err != nil {
+			return nil, err
+		}
+		result = strconv.AppendBool(result, value)
+	case Array:
+		if len(node.children) == 0 {
+			return append(result, "[]"...), nil
+		}
+		for i, child := range node.children {
+			if i != 0 {
+				result = appendYAMLIndent(result, indent)
+			}
+			result = append(result, '-', ' ')
+			if result, err = appendYAML(result, child, indent+2); err != nil {
+				return nil, err
+			}
+		}
+	case Object:
+		if len(node.children) == 0 {
+			return append(result, "{}"...), nil
+		}
+		for i, child := range node.children {
+			if child == nil {
+				return nil, errorUnparsed()
+			}
+			if i != 0 {
+				result = appendYAMLIndent(result, indent)
+			}
+			result = append(appendYAMLString(result, child.Key()), ':')
+			if child.isContainer() && len(child.children) != 0 {
+				result = appendYAMLIndent(result, indent+2)
+			} else {
+				result = append(result, ' ')
+			}
+			if result, err = appendYAML(result, child, indent+2); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errorType()
+	}
+	return result, nil
+}
+
+// appendYAMLIndent starts the new line with the indent
+func appendYAMLIndent(result []byte, indent int) []byte {
+	result = append(result, '\n')
+	for i := 0; i < indent; i++ {
+		result = append(result, ' ')
+	}
+	return result
+}
+
+// appendYAMLString appends the string as the plain scalar, if it's possible, or as the double-quoted one
+func appendYAMLString(result []byte, value string) []byte {
+	if yamlPlain(value) {
+		return append(result, value...)
+	}
+	result = append(result, quotes)
+	result = MarshalOptions{DisableHTMLEscape: true}.appendString(result, value)
+	return append(result, quotes)
+}
+
+// yamlPlain checks if the string can be written as the plain scalar and will be read back as the same string
+func yamlPlain(value string) bool {
+	if value == "" || yamlBooleans[value] || yamlResolve(value)._type != String ||
+		strings.IndexByte("-?:,[]{}#&*!|>'\"%@` \t", value[0]) != -1 ||
+		strings.IndexByte(": \t", value[len(value)-1]) != -1 ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") {
+		return false
+	}
+	for _, r := range value {
+		if r < ' ' || r >= 0x7f && r <= 0x9f || r == 0x2028 || r == 0x2029 || r == 0xfeff || r == 0xfffd {
+			return false
+		}
+	}
+	return true
+}
+
+// property returns the anchor, the alias or the tag
+func (p *yamlParser) property() string {
+	start := p.pos
+	for !p.eof() && !isYAMLSpace(p.data[p.pos]) && !isFlowIndicator(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func (p *yamlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// peek returns the symbol with the offset from the position, 0 after the end of data
+func (p *yamlParser) peek(offset int) byte {
+	if p.pos+offset < len(p.data) {
+		return p.data[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *yamlParser) atEOL() bool {
+	return p.eof() || isYAMLBreak(p.data[p.pos])
+}
+
+func (p *yamlParser) isEntry() bool {
+	return p.peek(0) == '-' && isYAMLSpace(p.peek(1))
+}
+
+func (p *yamlParser) isQuote() bool {
+	return p.peek(0) == '"' || p.peek(0) == '\''
+}
+
+// column returns the column of the position, starting from 0
+func (p *yamlParser) column() int {
+	return p.pos - (bytes.LastIndexByte(p.data[:p.pos], '\n') + 1)
+}
+
+// inline checks if there is anything before the position in the line, except spaces
+func (p *yamlParser) inline() bool {
+	for i := p.pos - 1; i >= 0 && p.data[i] != '\n'; i-- {
+		if p.data[i] != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// marker returns the document marker "---" or "..." at the position
+func (p *yamlParser) marker() string {
+	if p.column() != 0 || p.pos+3 > len(p.data) {
+		return ""
+	}
+	if marker := string(p.data[p.pos : p.pos+3]); (marker == "---" || marker == "...") && isYAMLSpace(p.peek(3)) {
+		return marker
+	}
+	return ""
+}
+
+// newline skips the line break
+func (p *yamlParser) newline() {
+	if p.peek(0) == '\r' {
+		p.pos++
+	}
+	if p.peek(0) == '\n' {
+		p.pos++
+	}
+}
+
+// skipBlanks skips spaces and tabs
+func (p *yamlParser) skipBlanks() {
+	for !p.eof() && isYAMLBlank(p.data[p.pos]) {
+		p.pos++
+	}
+}
+
+// skipLine skips spaces and the comment up to the end of the line
+func (p *yamlParser) skipLine() {
+	p.skipBlanks()
+	if p.peek(0) == '#' && (p.pos == 0 || isYAMLSpace(p.data[p.pos-1])) {
+		for !p.atEOL() {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips spaces, comments and line breaks
+func (p *yamlParser) skipBlank() {
+	for {
+		p.skipLine()
+		if p.eof() || !isYAMLBreak(p.data[p.pos]) {
+			return
+		}
+		p.newline()
+	}
+}
+
+func (p *yamlParser) error() error {
+	if p.eof() {
+		return Error{
+			Type:  UnexpectedEOF,
+			Index: p.pos,
+		}
+	}
+	return errorAt(p.pos, p.data[p.pos])
+}
+
+func isYAMLBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isYAMLBreak(c byte) bool {
+	return c == '\n' || c == '\r'
+}
+
+// isYAMLSpace checks if the symbol is a white space, a line break or the end of data
+func isYAMLSpace(c byte) bool {
+	return c == 0 || isYAMLBlank(c) || isYAMLBreak(c)
+}
+
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
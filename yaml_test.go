@@ -0,0 +1,261 @@
+package ajson
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "empty", input: ``, expected: `null`},
+		{name: "scalar", input: `value`, expected: `"value"`},
+		{name: "document", input: "%YAML 1.2\n--- # comment\nkey: value\n...\n", expected: `{"key":"value"}`},
+		{
+			name:     "mapping",
+			input:    "name: ajson # comment\nversion: 1.0\n\n# comment\nempty:\n\"quoted key\": 'x'\n",
+			expected: `{"name":"ajson","version":1,"empty":null,"quoted key":"x"}`,
+		},
+		{
+			name:     "nested",
+			input:    "a:\n  b:\n    c: 1\n  d: 2\ne: 3\n",
+			expected: `{"a":{"b":{"c":1},"d":2},"e":3}`,
+		},
+		{
+			name:     "sequence",
+			input:    "- a\n-\n- - b\n  - c\n-\n  d\n",
+			expected: `["a",null,["b","c"],"d"]`,
+		},
+		{
+			name:     "sequence in mapping",
+			input:    "a:\n- 1\n- 2\nb:\n  - x: 1\n    y: 2\n  - z\n",
+			expected: `{"a":[1,2],"b":[{"x":1,"y":2},"z"]}`,
+		},
+		{
+			name:     "flow",
+			input:    "a: [1, [2], {b: c, d}, e: f]\ng: {\"h\":1, 'i': [ ], j: {}}\nk: [\n  l,\n  m,\n]\n",
+			expected: `{"a":[1,[2],{"b":"c","d":null},{"e":"f"}],"g":{"h":1,"i":[],"j":{}},"k":["l","m"]}`,
+		},
+		{name: "json", input: `{"a": [true, false, null, -1.5e3, "\u00e9"]}`, expected: `{"a":[true,false,null,-1500,"é"]}`},
+		{
+			name:     "core schema",
+			input:    "[~, Null, TRUE, False, 0o17, 0x1F, +1, .5, 1., yes, 1_000, 0x, 2001-12-14, !!str 1, !!int \"2\", ! true]",
+			expected: `[null,null,true,false,15,31,1,0.5,1,"yes","1_000","0x","2001-12-14","1",2,"true"]`,
+		},
+		{
+			name:     "plain",
+			input:    "a: http://example.com/#anchor # comment\nb: multi\n  line\n\n  text\nc: a:b, c\n",
+			expected: `{"a":"http://example.com/#anchor","b":"multi line\ntext","c":"a:b, c"}`,
+		},
+		{
+			name:     "quoted",
+			input:    "a: 'it''s \"x\"'\nb: \"\\t\\x41\\u00e9\\U0001F600\\\\\\\"\\/\\N\\_\"\nc: \"folded\n  line\n\n  text \\\n  escaped\"\n",
+			expected: "{\"a\":\"it's \\\"x\\\"\",\"b\":\"\\tAé😀\\\\\\\"/\u0085\u00a0\",\"c\":\"folded line\\ntext escaped\"}",
+		},
+		{
+			name:     "literal",
+			input:    "a: |\n  line 1\n    line 2\n\n  line 3\n\n\nb: 1\n",
+			expected: `{"a":"line 1\n  line 2\n\nline 3\n","b":1}`,
+		},
+		{
+			name:     "folded",
+			input:    "a: >\n  folded\n  line\n\n  next\n    more indented\n  last\n",
+			expected: `{"a":"folded line\nnext\n  more indented\nlast\n"}`,
+		},
+		{
+			name:     "chomping",
+			input:    "a: |-\n  strip\n\nb: |+\n  keep\n\nc: |2\n    explicit\n",
+			expected: `{"a":"strip","b":"keep\n\n","c":"  explicit\n"}`,
+		},
+		{
+			name:     "anchors",
+			input:    "base: &base\n  a: 1\ncopy: *base\nlist: [&x 2, *x]\n",
+			expected: `{"base":{"a":1},"copy":{"a":1},"list":[2,2]}`,
+		},
+		{
+			name:     "merge",
+			input:    "base: &base\n  a: 1\n  b: 2\ncopy:\n  b: 3\n  <<: *base\n  c: 4\n",
+			expected: `{"base":{"a":1,"b":2},"copy":{"b":3,"c":4,"a":1}}`,
+		},
+		{
+			name:     "merge sequence",
+			input:    "x: &x {a: 1}\ny: &y {a: 2, b: 2}\nz:\n  <<: [*x, *y]\n",
+			expected: `{"x":{"a":1},"y":{"a":2,"b":2},"z":{"a":1,"b":2}}`,
+		},
+		{name: "merge flow", input: "[&x {a: 1}, {<<: *x, b: 2}]", expected: `[{"a":1},{"b":2,"a":1}]`},
+		{name: "quoted merge key", input: "\"<<\": 1\n", expected: `{"\u003c\u003c":1}`},
+		{name: "windows line breaks", input: "a: 1\r\nb:\r\n  - 2\r\n", expected: `{"a":1,"b":[2]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := UnmarshalYAML([]byte(test.input))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			result, err := Marshal(root)
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalYAML_special(t *testing.T) {
+	root, err := UnmarshalYAML([]byte("[.inf, -.Inf, .NaN]"))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	if value := root.MustIndex(0).MustNumeric(); !math.IsInf(value, 1) {
+		t.Errorf("Wrong value: %v", value)
+	}
+	if value := root.MustIndex(1).MustNumeric(); !math.IsInf(value, -1) {
+		t.Errorf("Wrong value: %v", value)
+	}
+	if value := root.MustIndex(2).MustNumeric(); !math.IsNaN(value) {
+		t.Errorf("Wrong value: %v", value)
+	}
+}
+
+func TestUnmarshalYAML_limits(t *testing.T) {
+	laughs := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for i := 'b'; i <= 'j'; i++ {
+		laughs += fmt.Sprintf("%c: &%[1]c [*%c, *%[2]c, *%[2]c, *%[2]c, *%[2]c, *%[2]c, *%[2]c, *%[2]c, *%[2]c, *%[2]c]\n", i, i-1)
+	}
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "flow", input: strings.Repeat("[", 10001), err: "max depth 10000 exceeded at 10000"},
+		{name: "flow mapping", input: strings.Repeat("{a: ", 10001), err: "max depth 10000 exceeded at 39997"},
+		{name: "block", input: strings.Repeat("- ", 10001) + "a", err: "max depth 10000 exceeded at 19999"},
+		{name: "aliases", input: laughs, err: "wrong request: aliases copy more than 1000000 nodes at 260"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalYAML([]byte(test.input))
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+	if _, err := UnmarshalYAML([]byte(strings.Repeat("[", 9999) + strings.Repeat("]", 9999))); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestUnmarshalYAML_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "duplicate", input: "a: 1\na: 2\n", err: "duplicate key 'a' at 5"},
+		{name: "duplicate flow", input: "{a: 1, a: 2}", err: "duplicate key 'a' at 7"},
+		{name: "mapping in value", input: "a: b: c\n", err: "wrong symbol ':' at 4"},
+		{name: "sequence in value", input: "a: - b\n", err: "wrong symbol '-' at 3"},
+		{name: "wrong indent", input: "a:\n  b: 1\n c: 2\n", err: "wrong symbol 'c' at 11"},
+		{name: "sequence after mapping", input: "a: 1\n- b\n", err: "wrong symbol '-' at 5"},
+		{name: "unclosed flow", input: "[1, 2", err: "unexpected end of file"},
+		{name: "unclosed quote", input: "'a", err: "unexpected end of file"},
+		{name: "wrong escape", input: `"\q"`, err: "wrong symbol 'q' at 2"},
+		{name: "unknown alias", input: "a: *b\n", err: "wrong symbol '*' at 3"},
+		{name: "complex key", input: "? a\n: b\n", err: "wrong symbol '?' at 0"},
+		{name: "multiple documents", input: "a\n---\nb\n", err: "wrong symbol '-' at 2"},
+		{name: "wrong tag", input: "!!int a", err: "wrong symbol 'a' at 6"},
+		{name: "block header", input: "a: |x\n", err: "wrong symbol 'x' at 4"},
+		{name: "merge scalar", input: "a: 1\n<<: 2\n", err: "wrong symbol '<' at 5"},
+		{name: "merge sequence of scalars", input: "{<<: [1]}", err: "wrong symbol '<' at 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalYAML([]byte(test.input))
+			if err == nil {
+				t.Errorf("Expected error")
+			} else if err.Error() != test.err {
+				t.Errorf("Wrong error: %s, expected: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "null", input: `null`, expected: "null\n"},
+		{name: "scalar", input: `"value"`, expected: "value\n"},
+		{name: "empty", input: `[[], {}]`, expected: "- []\n- {}\n"},
+		{
+			name:     "object",
+			input:    `{"name": "ajson", "tags": ["json", "yaml"], "nested": {"a": {"b": 1.5}}, "ok": true}`,
+			expected: "name: ajson\ntags:\n  - json\n  - yaml\nnested:\n  a:\n    b: 1.5\nok: true\n",
+		},
+		{
+			name:     "array",
+			input:    `[{"a": 1, "b": [2, 3]}, [4, [5]]]`,
+			expected: "- a: 1\n  b:\n    - 2\n    - 3\n- - 4\n  - - 5\n",
+		},
+		{
+			name:  "quoted",
+			input: `["", "1", "true", "null", "yes", "- a", "a: b", "a #b", "a:", " a", "line\nbreak", "<&>", "ключ", "a-b"]`,
+			expected: "- \"\"\n- \"1\"\n- \"true\"\n- \"null\"\n- \"yes\"\n- \"- a\"\n- \"a: b\"\n- \"a #b\"\n- \"a:\"\n- \" a\"\n" +
+				"- \"line\\nbreak\"\n- <&>\n- ключ\n- a-b\n",
+		},
+		{name: "keys", input: `{"1": 1, "": 2, "a b": 3}`, expected: "\"1\": 1\n\"\": 2\na b: 3\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := MarshalYAML(Must(Unmarshal([]byte(test.input))))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(result) != test.expected {
+				t.Errorf("Wrong result:\n%s\nexpected:\n%s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML_special(t *testing.T) {
+	root := ArrayNode("", []*Node{NumericNode("", math.Inf(1)), NumericNode("", math.Inf(-1)), NumericNode("", math.NaN())})
+	result, err := MarshalYAML(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if string(result) != "- .inf\n- -.inf\n- .nan\n" {
+		t.Errorf("Wrong result: %s", result)
+	}
+	if _, err = MarshalYAML(nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
+
+func TestMarshalYAML_roundTrip(t *testing.T) {
+	input := `{"a": ["", "x: y", "#", "'", "\"", "\\", "\t", "\u2028", "é", "0o1", "-", "1e3"], "b": {"c": [[{}], {"d": null}]}, "- e": -0.5}`
+	root := Must(Unmarshal([]byte(input)))
+	data, err := MarshalYAML(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	result, err := UnmarshalYAML(data)
+	if err != nil {
+		t.Errorf("Unexpected error: %s\n%s", err, data)
+		return
+	}
+	if ok, err := result.Eq(root); err != nil || !ok {
+		t.Errorf("Wrong result: %s, expected: %s", result, root)
+	}
+}